@@ -0,0 +1,323 @@
+// Command keymint-emulator serves a local emulation of the Keymint API, for manual QA and
+// integration environments without network access.
+//
+// It serves every endpoint used by the SDK, backed by the in-memory fake of the keymintest
+// package: it generates license keys and enforces activation limits, allowed hosts, expiry,
+// blocking and floating sessions with heartbeat expiry. The state is persisted to a JSON
+// file, and changes can be delivered as webhooks signed like the Keymint API signs them, so
+// that receivers can check them with keymint.VerifyWebhookSignature.
+//
+// Usage:
+//
+//	keymint-emulator -addr 127.0.0.1:8787 -state keymint-emulator.json \
+//		-webhook-url http://localhost:3000/webhooks/keymint -webhook-secret whsec_local
+//
+// Point the client at it with:
+//
+//	client, err := keymint.New(apiKey, "http://127.0.0.1:8787")
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/keymint-dev/keymint-go/src/keymintest"
+)
+
+// config holds the command-line flags.
+type config struct {
+	addr              string
+	statePath         string
+	apiKey            string
+	webhookURL        string
+	webhookSecret     string
+	heartbeatInterval time.Duration
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.addr, "addr", "127.0.0.1:8787", "address to listen on")
+	flag.StringVar(&cfg.statePath, "state", "keymint-emulator.json", "JSON file the state is loaded from and saved to")
+	flag.StringVar(&cfg.apiKey, "api-key", "", "API key clients must send (any key is accepted when empty)")
+	flag.StringVar(&cfg.webhookURL, "webhook-url", "", "URL webhooks are delivered to (no webhooks when empty)")
+	flag.StringVar(&cfg.webhookSecret, "webhook-secret", "", "secret webhooks are signed with")
+	flag.DurationVar(&cfg.heartbeatInterval, "heartbeat-interval", time.Minute, "floating license heartbeat interval")
+	flag.Parse()
+
+	if err := run(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves the emulator until it receives SIGINT or SIGTERM.
+func run(cfg config) error {
+	if cfg.webhookURL != "" && cfg.webhookSecret == "" {
+		return errors.New("-webhook-secret is required with -webhook-url")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	webhooks := newWebhookSender(cfg.webhookURL, cfg.webhookSecret)
+	defer webhooks.close()
+
+	var expired atomic.Bool
+	fake := keymintest.New(
+		keymintest.WithHeartbeatInterval(cfg.heartbeatInterval),
+		keymintest.WithEventHook(func(event keymintest.Event) {
+			if event.Type == keymintest.EventSessionExpired {
+				expired.Store(true)
+			}
+			log.Printf("event %s %v", event.Type, event.Data)
+			webhooks.send(event)
+		}),
+	)
+
+	store := &stateFile{path: cfg.statePath, fake: fake}
+	if err := store.load(); err != nil {
+		return err
+	}
+	defer store.save()
+
+	var api http.Handler = fake
+	if cfg.apiKey != "" {
+		api = keymintest.RequireAPIKey(cfg.apiKey, fake)
+	}
+
+	server := &http.Server{
+		Addr:              cfg.addr,
+		Handler:           logRequests(persist(store, api)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Sessions expire lazily in the fake; expire them eagerly to deliver their webhooks.
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fake.ExpireSessions()
+				if expired.Swap(false) {
+					store.save()
+				}
+			}
+		}
+	}()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("keymint emulator listening on http://%s (state: %s)", cfg.addr, cfg.statePath)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// stateFile persists the state of the fake to a JSON file.
+type stateFile struct {
+	mu   sync.Mutex
+	path string
+	fake *keymintest.Fake
+}
+
+// load restores the state saved in the file, if it exists.
+func (s *stateFile) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var state keymintest.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse state %s: %w", s.path, err)
+	}
+	return s.fake.Restore(state)
+}
+
+// save writes the current state to the file, atomically replacing it.
+func (s *stateFile) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(s.fake.Snapshot(), "", "  ")
+	if err != nil {
+		log.Printf("failed to encode state: %v", err)
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".keymint-emulator-*.json")
+	if err != nil {
+		log.Printf("failed to save state: %v", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		log.Printf("failed to save state: %v", err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		log.Printf("failed to save state: %v", err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		log.Printf("failed to save state: %v", err)
+	}
+}
+
+// persist saves the state after every request that may have changed it.
+func persist(store *stateFile, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Method != http.MethodGet {
+			store.save()
+		}
+	})
+}
+
+// statusRecorder records the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs every request with its status and duration.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
+
+// webhookSender delivers events as signed webhooks, in order, from a background goroutine.
+// A nil *webhookSender drops every event.
+type webhookSender struct {
+	url    string
+	secret string
+	client *http.Client
+	events chan keymintest.Event
+	done   chan struct{}
+}
+
+// newWebhookSender starts delivering webhooks to url, or returns nil if url is empty.
+func newWebhookSender(url, secret string) *webhookSender {
+	if url == "" {
+		return nil
+	}
+
+	w := &webhookSender{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+		events: make(chan keymintest.Event, 256),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(w.done)
+		for event := range w.events {
+			w.deliver(event)
+		}
+	}()
+	return w
+}
+
+// send queues event for delivery. Events are dropped when the queue is full, so that a slow
+// receiver never blocks the API.
+func (w *webhookSender) send(event keymintest.Event) {
+	if w == nil {
+		return
+	}
+	select {
+	case w.events <- event:
+	default:
+		log.Printf("webhook queue full, dropped event %s %s", event.ID, event.Type)
+	}
+}
+
+// close delivers the queued events and stops the sender.
+func (w *webhookSender) close() {
+	if w == nil {
+		return
+	}
+	close(w.events)
+	<-w.done
+}
+
+// deliver posts event to the webhook URL.
+func (w *webhookSender) deliver(event keymintest.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("failed to encode event %s: %v", event.ID, err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("failed to deliver webhook %s: %v", event.ID, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Keymint-Signature", signWebhook(payload, w.secret, time.Now()))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		log.Printf("failed to deliver webhook %s: %v", event.ID, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("webhook %s %s rejected with status %d", event.ID, event.Type, resp.StatusCode)
+	}
+}
+
+// signWebhook returns the Keymint-Signature header of payload: "t=<unix time>,v1=<signature>",
+// where the signature is the hex HMAC-SHA256 of "<unix time>.<payload>" keyed with secret.
+func signWebhook(payload []byte, secret string, now time.Time) string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
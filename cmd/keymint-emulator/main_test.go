@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+	"github.com/keymint-dev/keymint-go/src/keymintest"
+)
+
+func TestStateFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	fake := keymintest.New()
+	key, err := fake.CreateKey(keymint.CreateKeyParams{ProductID: "p", MaxActivations: keymint.Ptr(2), NewCustomer: &keymint.NewCustomer{Name: "Ada", Email: keymint.Ptr("ada@example.com")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fake.ActivateKey(keymint.ActivateKeyParams{ProductID: "p", LicenseKey: key.Key, HostID: keymint.Ptr("host")}); err != nil {
+		t.Fatal(err)
+	}
+	(&stateFile{path: path, fake: fake}).save()
+
+	restored := keymintest.New()
+	if err := (&stateFile{path: path, fake: restored}).load(); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(fake.Snapshot())
+	got, _ := json.Marshal(restored.Snapshot())
+	if string(got) != string(want) {
+		t.Errorf("restored state %s, want %s", got, want)
+	}
+	if _, err := restored.ActivateKey(keymint.ActivateKeyParams{ProductID: "p", LicenseKey: key.Key, HostID: keymint.Ptr("other")}); err != nil {
+		t.Errorf("the restored key cannot be activated: %v", err)
+	}
+}
+
+func TestStateFileLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := (&stateFile{path: filepath.Join(dir, "missing.json"), fake: keymintest.New()}).load(); err != nil {
+		t.Errorf("got error %v for a missing file, want an empty state", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&stateFile{path: corrupt, fake: keymintest.New()}).load(); err == nil {
+		t.Error("got no error for a corrupt file")
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"key.created"}`)
+
+	tests := []struct {
+		name    string
+		secret  string
+		signed  time.Time
+		payload string
+		wantErr bool
+	}{
+		{name: "valid", secret: "whsec_local", signed: time.Now(), payload: string(payload)},
+		{name: "other secret", secret: "whsec_other", signed: time.Now(), payload: string(payload), wantErr: true},
+		{name: "tampered payload", secret: "whsec_local", signed: time.Now(), payload: `{"id":"evt_2","type":"key.created"}`, wantErr: true},
+		{name: "expired", secret: "whsec_local", signed: time.Now().Add(-10 * time.Minute), payload: string(payload), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := signWebhook(payload, tt.secret, tt.signed)
+			err := keymint.VerifyWebhookSignature(tt.payload, header, "whsec_local", 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookSender(t *testing.T) {
+	received := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		received <- keymint.VerifyWebhookSignature(string(payload), r.Header.Get("Keymint-Signature"), "whsec_local", 0)
+	}))
+	defer server.Close()
+
+	sender := newWebhookSender(server.URL, "whsec_local")
+	sender.send(keymintest.Event{ID: "evt_1", Type: keymintest.EventKeyCreated, Created: time.Now()})
+	sender.close()
+
+	select {
+	case err := <-received:
+		if err != nil {
+			t.Errorf("the delivered webhook does not verify: %v", err)
+		}
+	default:
+		t.Error("no webhook was delivered")
+	}
+}
+
+func TestPersistAndRequireAPIKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	fake := keymintest.New()
+	store := &stateFile{path: path, fake: fake}
+	server := httptest.NewServer(persist(store, keymintest.RequireAPIKey("km_local", fake)))
+	defer server.Close()
+
+	other, _ := keymint.New("km_other", server.URL)
+	if _, err := other.CreateKey(keymint.CreateKeyParams{ProductID: "p"}); !errors.Is(err, keymint.ErrInvalidAPIKey) {
+		t.Errorf("got error %v for another API key, want ErrInvalidAPIKey", err)
+	}
+
+	client, _ := keymint.New("km_local", server.URL)
+	key, err := client.CreateKey(keymint.CreateKeyParams{ProductID: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("the state was not saved after CreateKey: %v", err)
+	}
+	if !strings.Contains(string(data), key.Key) {
+		t.Errorf("the saved state does not contain the created key:\n%s", data)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetKey(keymint.GetKeyParams{ProductID: "p", LicenseKey: key.Key}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the state was saved after a GET request (stat error %v)", err)
+	}
+}
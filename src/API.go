@@ -0,0 +1,79 @@
+package keymint
+
+import (
+	"context"
+	"iter"
+)
+
+// KeymintAPI is the set of API methods implemented by Client. Depend on it rather than
+// on *Client to substitute a test double, such as the in-memory fake of the keymintest package.
+type KeymintAPI interface {
+	// CreateKey creates a new license key.
+	CreateKey(params CreateKeyParams, opts ...*RequestOptions) (*CreateKeyResponse, error)
+	CreateKeyContext(ctx context.Context, params CreateKeyParams, opts ...*RequestOptions) (*CreateKeyResponse, error)
+
+	// ActivateKey activates a license key on a device.
+	ActivateKey(params ActivateKeyParams, opts ...*RequestOptions) (*ActivateKeyResponse, error)
+	ActivateKeyContext(ctx context.Context, params ActivateKeyParams, opts ...*RequestOptions) (*ActivateKeyResponse, error)
+
+	// DeactivateKey deactivates a device, or all devices, of a license key.
+	DeactivateKey(params DeactivateKeyParams, opts ...*RequestOptions) (*DeactivateKeyResponse, error)
+	DeactivateKeyContext(ctx context.Context, params DeactivateKeyParams, opts ...*RequestOptions) (*DeactivateKeyResponse, error)
+
+	// FloatingCheckout checks out a floating license seat.
+	FloatingCheckout(params FloatingCheckoutParams, opts ...*RequestOptions) (*FloatingCheckoutResponse, error)
+	FloatingCheckoutContext(ctx context.Context, params FloatingCheckoutParams, opts ...*RequestOptions) (*FloatingCheckoutResponse, error)
+
+	// FloatingHeartbeat keeps a floating license session alive.
+	FloatingHeartbeat(params FloatingHeartbeatParams, opts ...*RequestOptions) (*FloatingHeartbeatResponse, error)
+	FloatingHeartbeatContext(ctx context.Context, params FloatingHeartbeatParams, opts ...*RequestOptions) (*FloatingHeartbeatResponse, error)
+
+	// FloatingCheckin releases a floating license seat.
+	FloatingCheckin(params FloatingCheckinParams, opts ...*RequestOptions) (*FloatingCheckinResponse, error)
+	FloatingCheckinContext(ctx context.Context, params FloatingCheckinParams, opts ...*RequestOptions) (*FloatingCheckinResponse, error)
+
+	// GetKey retrieves the details of a license key.
+	GetKey(params GetKeyParams, opts ...*RequestOptions) (*GetKeyResponse, error)
+	GetKeyContext(ctx context.Context, params GetKeyParams, opts ...*RequestOptions) (*GetKeyResponse, error)
+
+	// BlockKey blocks a license key.
+	BlockKey(params BlockKeyParams, opts ...*RequestOptions) (*BlockKeyResponse, error)
+	BlockKeyContext(ctx context.Context, params BlockKeyParams, opts ...*RequestOptions) (*BlockKeyResponse, error)
+
+	// UnblockKey unblocks a license key.
+	UnblockKey(params UnblockKeyParams, opts ...*RequestOptions) (*UnblockKeyResponse, error)
+	UnblockKeyContext(ctx context.Context, params UnblockKeyParams, opts ...*RequestOptions) (*UnblockKeyResponse, error)
+
+	// CreateCustomer creates a new customer.
+	CreateCustomer(params CreateCustomerParams, opts ...*RequestOptions) (*CreateCustomerResponse, error)
+	CreateCustomerContext(ctx context.Context, params CreateCustomerParams, opts ...*RequestOptions) (*CreateCustomerResponse, error)
+
+	// GetAllCustomers lists customers.
+	GetAllCustomers(params GetAllCustomersParams, opts ...*RequestOptions) (*GetAllCustomersResponse, error)
+	GetAllCustomersContext(ctx context.Context, params GetAllCustomersParams, opts ...*RequestOptions) (*GetAllCustomersResponse, error)
+	// AllCustomers iterates over the customers, fetching the pages lazily.
+	AllCustomers(ctx context.Context, params GetAllCustomersParams, opts ...PaginateOption) iter.Seq2[Customer, error]
+
+	// GetCustomerWithKeys retrieves a customer and their license keys.
+	GetCustomerWithKeys(params GetCustomerWithKeysParams, opts ...*RequestOptions) (*GetCustomerWithKeysResponse, error)
+	GetCustomerWithKeysContext(ctx context.Context, params GetCustomerWithKeysParams, opts ...*RequestOptions) (*GetCustomerWithKeysResponse, error)
+
+	// UpdateCustomer updates a customer.
+	UpdateCustomer(params UpdateCustomerParams, opts ...*RequestOptions) (*UpdateCustomerResponse, error)
+	UpdateCustomerContext(ctx context.Context, params UpdateCustomerParams, opts ...*RequestOptions) (*UpdateCustomerResponse, error)
+
+	// DeleteCustomer deletes a customer.
+	DeleteCustomer(params DeleteCustomerParams, opts ...*RequestOptions) (*DeleteCustomerResponse, error)
+	DeleteCustomerContext(ctx context.Context, params DeleteCustomerParams, opts ...*RequestOptions) (*DeleteCustomerResponse, error)
+
+	// ToggleCustomerStatus enables or disables a customer.
+	ToggleCustomerStatus(params ToggleCustomerStatusParams, opts ...*RequestOptions) (*ToggleCustomerStatusResponse, error)
+	ToggleCustomerStatusContext(ctx context.Context, params ToggleCustomerStatusParams, opts ...*RequestOptions) (*ToggleCustomerStatusResponse, error)
+
+	// GetCustomerById retrieves a customer.
+	GetCustomerById(params GetCustomerByIdParams, opts ...*RequestOptions) (*GetCustomerByIdResponse, error)
+	GetCustomerByIdContext(ctx context.Context, params GetCustomerByIdParams, opts ...*RequestOptions) (*GetCustomerByIdResponse, error)
+}
+
+// Client implements KeymintAPI.
+var _ KeymintAPI = (*Client)(nil)
@@ -0,0 +1,192 @@
+package keymint
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen indicates the call was not sent because the circuit breaker is open.
+var ErrCircuitOpen = errors.New("keymint: circuit breaker open")
+
+// CircuitState is the state of the client's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request fast with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through to test whether the API recovered.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures the client's circuit breaker.
+//
+// The breaker counts transport failures and 5xx responses. After FailureThreshold
+// consecutive failures it opens, and calls fail fast with ErrCircuitOpen. After
+// OpenTimeout it becomes half-open and lets HalfOpenMaxRequests probe requests
+// through: a successful probe closes it, a failed one opens it again.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker (defaults to 5).
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before probing (defaults to 30 seconds).
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of concurrent probe requests allowed while half-open (defaults to 1).
+	HalfOpenMaxRequests int
+	// OnStateChange is called on every state change, e.g. to switch an app to offline validation.
+	// It is called synchronously from the request path and must not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+// WithCircuitBreaker enables a circuit breaker in the client's request path.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(c *Client) {
+		c.breaker = newCircuitBreaker(config)
+	}
+}
+
+// CircuitState returns the current state of the client's circuit breaker.
+// It is always CircuitClosed if the client has no circuit breaker.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.currentState()
+}
+
+// circuitBreaker implements the breaker configured with WithCircuitBreaker.
+// A nil *circuitBreaker lets every request through.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// newCircuitBreaker creates a closed circuit breaker, applying the config defaults.
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+	return &circuitBreaker{config: config}
+}
+
+// currentState returns the state, moving from open to half-open once OpenTimeout elapsed.
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	// Read the state before refresh updates it: in a single assignment, the order in which
+	// b.state and the call are evaluated is unspecified.
+	from := b.state
+	to := b.refresh(time.Now())
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return to
+}
+
+// refresh moves the breaker from open to half-open once OpenTimeout elapsed, and returns the state.
+// It must be called with b.mu held.
+func (b *circuitBreaker) refresh(now time.Time) CircuitState {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.state = CircuitHalfOpen
+		b.probes = 0
+	}
+	return b.state
+}
+
+// allow reports whether a request may be sent, returning an ApiError wrapping ErrCircuitOpen if not.
+// Every allowed request must be followed by a call to record.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	from := b.state
+	to := b.refresh(time.Now())
+	allowed := true
+	switch to {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if b.probes >= b.config.HalfOpenMaxRequests {
+			allowed = false
+		} else {
+			b.probes++
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(from, to)
+
+	if !allowed {
+		return &ApiError{
+			Message: "circuit breaker is open, the request was not sent",
+			Code:    -1,
+			kind:    ErrCircuitOpen,
+		}
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed request: err is the transport
+// error of the attempt, if any. Requests whose context was canceled are not counted.
+func (b *circuitBreaker) record(resp *http.Response, err error, canceled bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	from := b.state
+	if from == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	switch {
+	case canceled:
+		// The outcome says nothing about the API's health.
+	case err != nil || resp.StatusCode >= 500:
+		b.failures++
+		if from == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+		}
+	default:
+		b.failures = 0
+		b.state = CircuitClosed
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// notify calls OnStateChange if the state changed.
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(from, to)
+	}
+}
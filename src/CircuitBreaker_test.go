@@ -0,0 +1,136 @@
+package keymint
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stateChanges records the state changes reported by a circuit breaker.
+type stateChanges [][2]CircuitState
+
+func (s *stateChanges) record(from, to CircuitState) {
+	*s = append(*s, [2]CircuitState{from, to})
+}
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	var changes stateChanges
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: time.Hour, OnStateChange: changes.record})
+	failure := errors.New("connection refused")
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() = %v before the threshold", err)
+		}
+		b.record(nil, failure, false)
+	}
+	b.allow()
+	b.record(ok, nil, false)
+
+	// A success resets the count: three more failures are needed.
+	for i := 0; i < 3; i++ {
+		b.allow()
+		b.record(&http.Response{StatusCode: http.StatusBadGateway}, nil, false)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want ErrCircuitOpen", err)
+	}
+	if want := (stateChanges{{CircuitClosed, CircuitOpen}}); !slices.Equal(changes, want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestCircuitBreakerIgnoresCanceledRequests(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	b.allow()
+	b.record(nil, errors.New("context canceled"), true)
+	if state := b.currentState(); state != CircuitClosed {
+		t.Errorf("state = %v, want closed", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbes(t *testing.T) {
+	var changes stateChanges
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour, HalfOpenMaxRequests: 2, OnStateChange: changes.record})
+	b.allow()
+	b.record(nil, errors.New("timeout"), false)
+
+	// Let the open timeout elapse.
+	b.openedAt = time.Now().Add(-time.Hour)
+	if state := b.currentState(); state != CircuitHalfOpen {
+		t.Fatalf("state = %v, want half-open", state)
+	}
+
+	// Two probes may be in flight at once, not three.
+	if err := b.allow(); err != nil {
+		t.Fatalf("first probe: %v", err)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("second probe: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("third probe: allow() = %v, want ErrCircuitOpen", err)
+	}
+
+	// A completed probe frees its slot; a failed one opens the breaker again.
+	b.record(nil, errors.New("timeout"), false)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after a failed probe: allow() = %v, want ErrCircuitOpen", err)
+	}
+
+	b.openedAt = time.Now().Add(-time.Hour)
+	if err := b.allow(); err != nil {
+		t.Fatalf("probe after reopening: %v", err)
+	}
+	b.record(&http.Response{StatusCode: http.StatusOK}, nil, false)
+
+	want := stateChanges{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestClientCircuitBreakerFailsFast(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, `{"message":"down","code":-1}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, attempts := newTestClient(t, server.URL,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour}),
+	)
+	params := GetKeyParams{ProductID: "p", LicenseKey: "k"}
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetKey(params); !errors.Is(err, ErrServer) {
+			t.Fatalf("call %d: got %v, want ErrServer", i+1, err)
+		}
+	}
+
+	_, err := client.GetKey(params)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("server got %d requests, want 2", requests.Load())
+	}
+	if *attempts != 1 {
+		t.Errorf("got %d attempts, want 1", *attempts)
+	}
+	if state := client.CircuitState(); state != CircuitOpen {
+		t.Errorf("CircuitState() = %v, want open", state)
+	}
+}
@@ -0,0 +1,106 @@
+package keymint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnlimitedActivations is the CreateKeyParams.MaxActivations value of a license key that can
+// be activated on any number of devices. It is not sent: the API creates a key without
+// maxActivations as unlimited. It is also the MaxActivations reported for such keys.
+const UnlimitedActivations = 0
+
+// Ptr returns a pointer to v, to set the optional fields of params:
+//
+//	keymint.CreateKeyParams{ProductID: productId, MaxActivations: keymint.Ptr(3)}
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Nonce is the rotating nonce of a floating license session. It is returned as nextNonce by
+// checkouts and heartbeats, and sent back as the timestamp of the next heartbeat or checkin,
+// in the JSON form it was received in: a string, or a number.
+type Nonce struct {
+	// Value is the nonce, e.g. "a1b2c3" or "1705312800123". Sign requests over it with
+	// GenerateSessionSignature.
+	Value string
+	// Numeric is true if the nonce is encoded as a JSON number rather than a string.
+	Numeric bool
+}
+
+// String returns the value of the nonce.
+func (n Nonce) String() string {
+	return n.Value
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n Nonce) MarshalJSON() ([]byte, error) {
+	if n.Numeric {
+		if !json.Valid([]byte(n.Value)) || strings.IndexByte("-0123456789", n.Value[0]) < 0 {
+			return nil, fmt.Errorf("keymint: invalid numeric nonce %q", n.Value)
+		}
+		return []byte(n.Value), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Nonce) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*n = Nonce{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*n = Nonce{Value: value}
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("keymint: invalid nonce %s: expected a string or a number", data)
+	}
+	*n = Nonce{Value: number.String(), Numeric: true}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. MaxActivations is sent as a string, as the API
+// expects, and omitted if it is UnlimitedActivations.
+func (p CreateKeyParams) MarshalJSON() ([]byte, error) {
+	type params CreateKeyParams
+	wire := struct {
+		params
+		MaxActivations *string `json:"maxActivations,omitempty"`
+	}{params: params(p)}
+
+	if p.MaxActivations != nil && *p.MaxActivations != UnlimitedActivations {
+		maxActivations := strconv.Itoa(*p.MaxActivations)
+		wire.MaxActivations = &maxActivations
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. MaxActivations is accepted as a string or a number.
+func (p *CreateKeyParams) UnmarshalJSON(data []byte) error {
+	type params CreateKeyParams
+	wire := struct {
+		*params
+		MaxActivations *json.Number `json:"maxActivations"`
+	}{params: (*params)(p)}
+
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	p.MaxActivations = nil
+	if wire.MaxActivations != nil {
+		maxActivations, err := strconv.Atoi(wire.MaxActivations.String())
+		if err != nil {
+			return fmt.Errorf("keymint: invalid maxActivations %q: expected an integer", wire.MaxActivations.String())
+		}
+		p.MaxActivations = &maxActivations
+	}
+	return nil
+}
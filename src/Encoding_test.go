@@ -0,0 +1,78 @@
+package keymint
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateKeyParamsMaxActivations(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxActivations *int
+		want           string
+	}{
+		{"limited", Ptr(3), `{"productId":"p","maxActivations":"3"}`},
+		{"unlimited", Ptr(UnlimitedActivations), `{"productId":"p"}`},
+		{"unset", nil, `{"productId":"p"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(CreateKeyParams{ProductID: "p", MaxActivations: test.maxActivations})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.want {
+				t.Errorf("got %s, want %s", data, test.want)
+			}
+		})
+	}
+
+	for _, data := range []string{`{"maxActivations":"3"}`, `{"maxActivations":3}`} {
+		var params CreateKeyParams
+		if err := json.Unmarshal([]byte(data), &params); err != nil {
+			t.Fatalf("decoding %s: %v", data, err)
+		}
+		if params.MaxActivations == nil || *params.MaxActivations != 3 {
+			t.Errorf("decoding %s: got %v, want 3", data, params.MaxActivations)
+		}
+	}
+}
+
+func TestNonceRoundTrip(t *testing.T) {
+	tests := []struct {
+		data string
+		want Nonce
+	}{
+		{`"a1b2c3"`, Nonce{Value: "a1b2c3"}},
+		{`"1705312800123"`, Nonce{Value: "1705312800123"}},
+		{`1705312800123`, Nonce{Value: "1705312800123", Numeric: true}},
+		{`1.5e3`, Nonce{Value: "1.5e3", Numeric: true}},
+	}
+	for _, test := range tests {
+		var nonce Nonce
+		if err := json.Unmarshal([]byte(test.data), &nonce); err != nil {
+			t.Fatalf("decoding %s: %v", test.data, err)
+		}
+		if nonce != test.want {
+			t.Errorf("decoding %s: got %+v, want %+v", test.data, nonce, test.want)
+		}
+		data, err := json.Marshal(nonce)
+		if err != nil {
+			t.Fatalf("encoding %+v: %v", nonce, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("got %s, want %s", data, test.data)
+		}
+	}
+
+	var nonce Nonce
+	if err := json.Unmarshal([]byte(`null`), &nonce); err != nil || nonce != (Nonce{}) {
+		t.Errorf("decoding null: got %+v, %v", nonce, err)
+	}
+	if err := json.Unmarshal([]byte(`true`), &nonce); err == nil {
+		t.Error("decoded a boolean, want an error")
+	}
+	if _, err := json.Marshal(Nonce{Value: `"1"`, Numeric: true}); err == nil {
+		t.Error("encoded a numeric nonce that is not a number, want an error")
+	}
+}
@@ -0,0 +1,132 @@
+package keymint
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors describing the kind of an ApiError.
+// Use errors.Is to test for them:
+//
+//	if errors.Is(err, keymint.ErrKeyExpired) {
+//		// ask the user to renew
+//	}
+var (
+	// ErrInvalidAPIKey indicates the API key was rejected by the API.
+	ErrInvalidAPIKey = errors.New("keymint: invalid API key")
+	// ErrNotFound indicates the requested key, customer or session does not exist.
+	ErrNotFound = errors.New("keymint: not found")
+	// ErrKeyExpired indicates the license key has expired.
+	ErrKeyExpired = errors.New("keymint: license key expired")
+	// ErrKeyBlocked indicates the license key has been blocked.
+	ErrKeyBlocked = errors.New("keymint: license key blocked")
+	// ErrMaxActivations indicates the license key reached its maximum number of activations.
+	ErrMaxActivations = errors.New("keymint: maximum activations reached")
+	// ErrHostNotAllowed indicates the host is not in the license key's allowed hosts.
+	ErrHostNotAllowed = errors.New("keymint: host not allowed")
+	// ErrBadRequest indicates the API rejected the request parameters.
+	ErrBadRequest = errors.New("keymint: bad request")
+	// ErrRateLimited indicates the API rate limit was exceeded.
+	ErrRateLimited = errors.New("keymint: rate limited")
+	// ErrServer indicates the API failed with a 5xx status.
+	ErrServer = errors.New("keymint: server error")
+	// ErrRejected indicates the API answered with a successful HTTP status but a
+	// non-zero response code or a false status, and no more specific kind applies.
+	ErrRejected = errors.New("keymint: request rejected")
+	// ErrNetwork indicates the request never got a response (DNS, connection or TLS failure).
+	ErrNetwork = errors.New("keymint: network error")
+	// ErrValidation indicates the params failed local validation and the request was not sent.
+	// The error wraps a *ValidationError listing the invalid fields.
+	ErrValidation = errors.New("keymint: invalid params")
+)
+
+// messageKinds maps the exact messages of API errors to the kind of error they report,
+// compared case-insensitively. Other errors are classified by HTTP status.
+var messageKinds = map[string]error{
+	"license key has expired":                          ErrKeyExpired,
+	"license key is blocked":                           ErrKeyBlocked,
+	"maximum activations reached for this license key": ErrMaxActivations,
+	"host is not allowed for this license key":         ErrHostNotAllowed,
+	"invalid api key":                                  ErrInvalidAPIKey,
+}
+
+// Kind returns the sentinel error describing e, or nil if e does not match any known kind.
+//
+// Errors returned by the API are classified from a few exact messages, then from the
+// HTTP status. Failures reported in the body of a successful HTTP response default to
+// ErrRejected.
+func (e *ApiError) Kind() error {
+	if e.kind != nil {
+		return e.kind
+	}
+	if e.Status == nil {
+		return nil
+	}
+
+	// Client-side failures (e.g. an unreadable body) carry their own message,
+	// so only errors reported by the API are classified by message.
+	if e.err == nil {
+		if kind, ok := messageKinds[strings.ToLower(strings.TrimSpace(e.Message))]; ok {
+			return kind
+		}
+	}
+
+	switch status := *e.Status; {
+	case status == http.StatusUnauthorized:
+		return ErrInvalidAPIKey
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrServer
+	case status >= 400:
+		return ErrBadRequest
+	case e.err == nil:
+		return ErrRejected
+	}
+	return nil
+}
+
+// Is reports whether e is of the kind described by target, so that
+// errors.Is(err, keymint.ErrKeyBlocked) works on errors returned by the client.
+func (e *ApiError) Is(target error) bool {
+	kind := e.Kind()
+	return kind != nil && kind == target
+}
+
+// Unwrap returns the underlying error (e.g. a *url.Error, a context error or a
+// *json.SyntaxError) that caused e, or nil if the error came from the API.
+func (e *ApiError) Unwrap() error {
+	return e.err
+}
+
+// ContextError returns the error a call fails with when its context is done before it
+// completes: an ApiError with code -1 wrapping err, the context's error. It lets test
+// doubles of KeymintAPI, such as keymintest.Fake, fail like Client does.
+func ContextError(err error) *ApiError {
+	return &ApiError{
+		Message: fmt.Sprintf("request canceled: %v", err),
+		Code:    -1,
+		err:     err,
+	}
+}
+
+// IsRetryable reports whether err is a transient failure that may succeed if the request is retried.
+func IsRetryable(err error) bool {
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if errors.Is(apiErr, ErrNetwork) {
+		return true
+	}
+	return apiErr.Status != nil && isRetryableStatus(*apiErr.Status)
+}
+
+// IsNotFound reports whether err indicates the requested resource does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,103 @@
+package keymint
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApiErrorKind(t *testing.T) {
+	status := func(code int) *int { return &code }
+
+	tests := []struct {
+		name string
+		err  *ApiError
+		want error
+	}{
+		{"exact message", &ApiError{Message: "Maximum activations reached for this license key", Code: 99, Status: status(403)}, ErrMaxActivations},
+		{"message case and spaces", &ApiError{Message: " license key IS BLOCKED ", Status: status(403)}, ErrKeyBlocked},
+		{"message in successful response", &ApiError{Message: "License key has expired", Code: 4, Status: status(200)}, ErrKeyExpired},
+		{"message is not matched by substring", &ApiError{Message: "Session expired", Code: 99, Status: status(404)}, ErrNotFound},
+		{"blocked in another message", &ApiError{Message: "Request blocked by host policy", Status: status(403)}, ErrBadRequest},
+		{"code is not classified", &ApiError{Message: "Nope", Code: 3, Status: status(403)}, ErrBadRequest},
+		{"unauthorized", &ApiError{Message: "Unauthorized", Status: status(401)}, ErrInvalidAPIKey},
+		{"rate limited", &ApiError{Message: "Too many requests", Status: status(429)}, ErrRateLimited},
+		{"unknown code in successful response", &ApiError{Message: "Nope", Code: 42, Status: status(200)}, ErrRejected},
+		{"client failure ignores message", &ApiError{Message: "License key has expired", Code: -1, Status: status(200), err: io.ErrUnexpectedEOF}, nil},
+		{"no status", &ApiError{Message: "failed to marshal request", Code: -1}, nil},
+		{"explicit kind", &ApiError{Message: "request failed", Code: -1, kind: ErrNetwork}, ErrNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Kind(); got != tt.want {
+				t.Errorf("Kind() = %v, want %v", got, tt.want)
+			}
+			if tt.want != nil && !errors.Is(tt.err, tt.want) {
+				t.Errorf("errors.Is(err, %v) = false", tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	status := func(code int) *int { return &code }
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&ApiError{Message: "unavailable", Status: status(http.StatusServiceUnavailable)}, true},
+		{&ApiError{Message: "slow down", Status: status(http.StatusTooManyRequests)}, true},
+		{&ApiError{Message: "bad", Status: status(http.StatusBadRequest)}, false},
+		{&ApiError{Message: "request failed", Code: -1, kind: ErrNetwork}, true},
+		{errors.New("other"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorResponseBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		message string
+		code    int
+		kind    error
+	}{
+		{"status false", http.StatusForbidden, `{"status":false,"message":"License key is blocked","code":3}`, "License key is blocked", 3, ErrKeyBlocked},
+		{"status true", http.StatusForbidden, `{"status":true,"message":"License key has expired","code":4}`, "License key has expired", 4, ErrKeyExpired},
+		{"without code", http.StatusNotFound, `{"message":"License key not found"}`, "License key not found", 0, ErrNotFound},
+		{"unknown message", http.StatusConflict, `{"status":false,"message":"A customer with this email already exists","code":10}`, "A customer with this email already exists", 10, ErrBadRequest},
+		{"not json", http.StatusBadGateway, `<html>Bad Gateway</html>`, "API error: <html>Bad Gateway</html>", -1, ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req_1")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := newTestClient(t, server.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+			_, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: "k"})
+
+			var apiErr *ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an ApiError", err)
+			}
+			if apiErr.Message != tt.message || apiErr.Code != tt.code || *apiErr.Status != tt.status || apiErr.RequestID != "req_1" {
+				t.Errorf("got %+v, want message %q, code %d and status %d", apiErr, tt.message, tt.code, tt.status)
+			}
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("got kind %v, want %v", apiErr.Kind(), tt.kind)
+			}
+		})
+	}
+}
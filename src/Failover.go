@@ -0,0 +1,108 @@
+package keymint
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultPrimaryRetryInterval is how long the client sticks to a fallback base URL
+// before trying the primary again, unless overridden with WithPrimaryRetryInterval.
+const defaultPrimaryRetryInterval = time.Minute
+
+// WithBaseURLs sets an ordered list of API base URLs, e.g. a primary endpoint followed
+// by regional mirrors or corporate proxies. When a request fails with a connection error
+// or a 5xx status, it is sent to the next base URL. The client remembers which base URL
+// is healthy, and periodically tries the primary again (see WithPrimaryRetryInterval).
+// It takes precedence over the baseURL argument of New and over WithBaseURL.
+func WithBaseURLs(baseURLs ...string) Option {
+	return func(c *Client) {
+		c.baseURLs = nil
+		for _, baseURL := range baseURLs {
+			if baseURL != "" {
+				c.baseURLs = append(c.baseURLs, baseURL)
+			}
+		}
+	}
+}
+
+// WithPrimaryRetryInterval sets how long the client keeps using a fallback base URL
+// before trying the primary one again (defaults to 1 minute).
+func WithPrimaryRetryInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.primaryRetryInterval = interval
+	}
+}
+
+// endpointPool tracks the health of the client's base URLs.
+type endpointPool struct {
+	mu            sync.Mutex
+	baseURLs      []string
+	current       int
+	failedOverAt  time.Time
+	retryInterval time.Duration
+}
+
+// newEndpointPool creates a pool starting on the first (primary) base URL.
+func newEndpointPool(baseURLs []string, retryInterval time.Duration) *endpointPool {
+	if retryInterval <= 0 {
+		retryInterval = defaultPrimaryRetryInterval
+	}
+	return &endpointPool{
+		baseURLs:      baseURLs,
+		retryInterval: retryInterval,
+	}
+}
+
+// preferred returns the base URL requests should currently be sent to.
+func (p *endpointPool) preferred() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.baseURLs[p.current]
+}
+
+// order returns the base URLs to try, in order, for a request targeting baseURL.
+// A base URL outside the pool (e.g. a per-request override) is tried alone. Otherwise
+// the healthy base URL comes first, or the primary once the retry interval elapsed,
+// followed by the others in their configured order.
+func (p *endpointPool) order(baseURL string, now time.Time) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.baseURLs) < 2 || !slices.Contains(p.baseURLs, baseURL) {
+		return []string{baseURL}
+	}
+
+	start := p.current
+	if start != 0 && now.Sub(p.failedOverAt) >= p.retryInterval {
+		start = 0
+	}
+
+	ordered := make([]string, 0, len(p.baseURLs))
+	ordered = append(ordered, p.baseURLs[start])
+	for i, candidate := range p.baseURLs {
+		if i != start {
+			ordered = append(ordered, candidate)
+		}
+	}
+	return ordered
+}
+
+// healthy records that baseURL answered a request.
+func (p *endpointPool) healthy(baseURL string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := slices.Index(p.baseURLs, baseURL)
+	switch {
+	case i < 0:
+		return
+	case i == 0:
+		p.current = 0
+	case i != p.current || now.Sub(p.failedOverAt) >= p.retryInterval:
+		// Either a fail-over, or the primary was just retried and is still failing:
+		// stay on the fallback for another interval.
+		p.current = i
+		p.failedOverAt = now
+	}
+}
@@ -0,0 +1,88 @@
+package keymint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEndpointPoolOrder(t *testing.T) {
+	start := time.Now()
+	pool := newEndpointPool([]string{"primary", "mirror", "proxy"}, time.Minute)
+
+	check := func(name, baseURL string, now time.Time, want ...string) {
+		t.Helper()
+		if got := pool.order(baseURL, now); !slices.Equal(got, want) {
+			t.Errorf("%s: order() = %v, want %v", name, got, want)
+		}
+	}
+
+	check("initially", "primary", start, "primary", "mirror", "proxy")
+	check("override", "https://staging", start, "https://staging")
+
+	pool.healthy("proxy", start)
+	if got := pool.preferred(); got != "proxy" {
+		t.Errorf("preferred() = %q, want proxy", got)
+	}
+	check("after failing over", "proxy", start.Add(time.Second), "proxy", "primary", "mirror")
+	check("after the retry interval", "proxy", start.Add(time.Minute), "primary", "mirror", "proxy")
+
+	// The primary was retried and failed again: stay on the fallback for another interval.
+	pool.healthy("proxy", start.Add(time.Minute))
+	check("after a failed retry", "proxy", start.Add(90*time.Second), "proxy", "primary", "mirror")
+	check("after the next interval", "proxy", start.Add(2*time.Minute), "primary", "mirror", "proxy")
+
+	pool.healthy("primary", start.Add(2*time.Minute))
+	check("after the primary recovered", "primary", start.Add(2*time.Minute), "primary", "mirror", "proxy")
+}
+
+func TestClientFailsOverAndReturnsToPrimary(t *testing.T) {
+	var primaryDown atomic.Bool
+	primaryDown.Store(true)
+	var primaryRequests, mirrorRequests atomic.Int32
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryRequests.Add(1)
+		if primaryDown.Load() {
+			http.Error(w, `{"message":"down","code":-1}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorRequests.Add(1)
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer mirror.Close()
+
+	client, _ := newTestClient(t, "", WithBaseURLs(primary.URL, mirror.URL), WithPrimaryRetryInterval(50*time.Millisecond))
+	params := GetKeyParams{ProductID: "p", LicenseKey: "k"}
+	call := func() {
+		t.Helper()
+		if _, err := client.GetKey(params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts := func(wantPrimary, wantMirror int32) {
+		t.Helper()
+		if primaryRequests.Load() != wantPrimary || mirrorRequests.Load() != wantMirror {
+			t.Errorf("requests: primary %d, mirror %d; want %d and %d", primaryRequests.Load(), mirrorRequests.Load(), wantPrimary, wantMirror)
+		}
+	}
+
+	call()
+	counts(1, 1)
+	call()
+	counts(1, 2)
+
+	time.Sleep(60 * time.Millisecond)
+	primaryDown.Store(false)
+	call()
+	counts(2, 2)
+	call()
+	counts(3, 2)
+}
@@ -206,7 +206,7 @@ func GetOrCreateInstallationID(storagePath string) (string, error) {
 // using the sessionSecret and the rotating nextNonce (passed as the timestamp).
 //
 // sessionID: The 22-character unique session ID.
-// nonce: The rotating nonce string (nextNonce) received from the previous response.
+// nonce: The Value of the rotating nonce (NextNonce) received from the previous response.
 // sessionSecret: The temporary session secret key received during checkout.
 // Returns a 64-character hexadecimal signature string.
 func GenerateSessionSignature(sessionID, nonce, sessionSecret string) string {
@@ -1,10 +1,9 @@
 package keymint
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -12,16 +11,33 @@ import (
 // Client is the main entry point for the KeyMint API client
 // Client provides methods to interact with the KeyMint API for license and customer management.
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	timeout      *time.Duration
+	userAgent    string
+	headers      http.Header
+	retryPolicy  RetryPolicy
+	interceptors []Interceptor
+	logger       *slog.Logger
+	tracer       Tracer
+	metrics      MetricsObserver
+	limiter      rateLimiter
+	breaker      *circuitBreaker
+
+	metadataSchema MetadataSchema
+
+	baseURLs             []string
+	primaryRetryInterval time.Duration
+	endpoints            *endpointPool
 }
 
 // New creates a new KeyMint API client instance.
 // apiKey: Your Keymint API key (required).
 // baseURL: Optional API base URL (defaults to https://api.keymint.dev).
+// opts: Optional client configurations (e.g. WithHTTPClient, WithTimeout).
 // Returns a new Client instance or an error if apiKey is missing.
-func New(apiKey string, baseURL string) (*Client, error) {
+func New(apiKey string, baseURL string, opts ...Option) (*Client, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required to initialize the client")
 	}
@@ -30,225 +46,38 @@ func New(apiKey string, baseURL string) (*Client, error) {
 		baseURL = "https://api.keymint.dev"
 	}
 
-	return &Client{
+	c := &Client{
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-	}, nil
-}
-
-// handleRequest is a generic method to handle POST/PUT requests.
-// method: HTTP method (POST/PUT).
-// endpoint: API endpoint.
-// params: Request body parameters.
-// result: Pointer to the result struct to unmarshal response into.
-// opts: Optional request configurations (e.g. idempotency keys).
-// Returns an error if the request fails or the API returns an error.
-func (c *Client) handleRequest(method, endpoint string, params interface{}, result interface{}, opts ...*RequestOptions) error {
-	jsonData, err := json.Marshal(params)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to marshal request: %v", err),
-			Code:    -1,
-		}
-	}
-
-	req, err := http.NewRequest(method, c.baseURL+endpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to create request: %v", err),
-			Code:    -1,
-		}
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	if len(opts) > 0 && opts[0] != nil && opts[0].IdempotencyKey != "" {
-		req.Header.Set("Idempotency-Key", opts[0].IdempotencyKey)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("request failed: %v", err),
-			Code:    -1,
-		}
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to read response: %v", err),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
-
-	if resp.StatusCode >= 400 {
-		var apiErr ApiError
-		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Message != "" {
-			apiErr.Status = &resp.StatusCode
-			return &apiErr
-		}
-		return &ApiError{
-			Message: fmt.Sprintf("API error: %s", string(body)),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
-
-	if err := json.Unmarshal(body, result); err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to unmarshal response: %v", err),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
-
-	return nil
-}
-
-// handleGetRequest is a generic method to handle GET requests.
-// endpoint: API endpoint.
-// queryParams: Query parameters as a map.
-// result: Pointer to the result struct to unmarshal response into.
-// Returns an error if the request fails or the API returns an error.
-func (c *Client) handleGetRequest(endpoint string, queryParams map[string]string, result interface{}) error {
-	req, err := http.NewRequest("GET", c.baseURL+endpoint, nil)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to create request: %v", err),
-			Code:    -1,
-		}
+		userAgent:   defaultUserAgent,
+		headers:     make(http.Header),
+		retryPolicy: DefaultRetryPolicy,
 	}
 
-	if queryParams != nil {
-		q := req.URL.Query()
-		for key, value := range queryParams {
-			q.Add(key, value)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
 		}
-		req.URL.RawQuery = q.Encode()
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("request failed: %v", err),
-			Code:    -1,
-		}
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to read response: %v", err),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
+	if c.timeout != nil {
+		httpClient := *c.httpClient
+		httpClient.Timeout = *c.timeout
+		c.httpClient = &httpClient
 	}
 
-	if resp.StatusCode >= 400 {
-		var apiErr ApiError
-		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Message != "" {
-			apiErr.Status = &resp.StatusCode
-			return &apiErr
-		}
-		return &ApiError{
-			Message: fmt.Sprintf("API error: %s", string(body)),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
+	if len(c.baseURLs) == 0 {
+		c.baseURLs = []string{c.baseURL}
 	}
+	c.baseURL = c.baseURLs[0]
+	c.endpoints = newEndpointPool(c.baseURLs, c.primaryRetryInterval)
 
-	if err := json.Unmarshal(body, result); err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to unmarshal response: %v", err),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
+	c.interceptors = append(c.instrumentation(), c.interceptors...)
 
-	return nil
-}
-
-// handleDeleteRequest is a generic method to handle DELETE requests.
-// endpoint: API endpoint.
-// queryParams: Query parameters as a map.
-// result: Pointer to the result struct to unmarshal response into.
-// opts: Optional request configurations (e.g. idempotency keys).
-// Returns an error if the request fails or the API returns an error.
-func (c *Client) handleDeleteRequest(endpoint string, queryParams map[string]string, result interface{}, opts ...*RequestOptions) error {
-	req, err := http.NewRequest("DELETE", c.baseURL+endpoint, nil)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to create request: %v", err),
-			Code:    -1,
-		}
-	}
-
-	if queryParams != nil {
-		q := req.URL.Query()
-		for key, value := range queryParams {
-			q.Add(key, value)
-		}
-		req.URL.RawQuery = q.Encode()
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	if len(opts) > 0 && opts[0] != nil && opts[0].IdempotencyKey != "" {
-		req.Header.Set("Idempotency-Key", opts[0].IdempotencyKey)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("request failed: %v", err),
-			Code:    -1,
-		}
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to read response: %v", err),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
-
-	if resp.StatusCode >= 400 {
-		var apiErr ApiError
-		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Message != "" {
-			apiErr.Status = &resp.StatusCode
-			return &apiErr
-		}
-		return &ApiError{
-			Message: fmt.Sprintf("API error: %s", string(body)),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
-
-	if err := json.Unmarshal(body, result); err != nil {
-		return &ApiError{
-			Message: fmt.Sprintf("failed to unmarshal response: %v", err),
-			Code:    -1,
-			Status:  &resp.StatusCode,
-		}
-	}
-
-	return nil
+	return c, nil
 }
 
 // CreateKey creates a new license key.
@@ -256,8 +85,14 @@ func (c *Client) handleDeleteRequest(endpoint string, queryParams map[string]str
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the created key information or an error.
 func (c *Client) CreateKey(params CreateKeyParams, opts ...*RequestOptions) (*CreateKeyResponse, error) {
+	return c.CreateKeyContext(context.Background(), params, opts...)
+}
+
+// CreateKeyContext is like CreateKey but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) CreateKeyContext(ctx context.Context, params CreateKeyParams, opts ...*RequestOptions) (*CreateKeyResponse, error) {
 	var result CreateKeyResponse
-	err := c.handleRequest("POST", "/key", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "CreateKey", method: "POST", path: "/key", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -270,8 +105,14 @@ func (c *Client) CreateKey(params CreateKeyParams, opts ...*RequestOptions) (*Cr
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the activation status or an error.
 func (c *Client) ActivateKey(params ActivateKeyParams, opts ...*RequestOptions) (*ActivateKeyResponse, error) {
+	return c.ActivateKeyContext(context.Background(), params, opts...)
+}
+
+// ActivateKeyContext is like ActivateKey but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) ActivateKeyContext(ctx context.Context, params ActivateKeyParams, opts ...*RequestOptions) (*ActivateKeyResponse, error) {
 	var result ActivateKeyResponse
-	err := c.handleRequest("POST", "/key/activate", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "ActivateKey", method: "POST", path: "/key/activate", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -280,8 +121,14 @@ func (c *Client) ActivateKey(params ActivateKeyParams, opts ...*RequestOptions)
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the deactivation confirmation or an error.
 func (c *Client) DeactivateKey(params DeactivateKeyParams, opts ...*RequestOptions) (*DeactivateKeyResponse, error) {
+	return c.DeactivateKeyContext(context.Background(), params, opts...)
+}
+
+// DeactivateKeyContext is like DeactivateKey but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) DeactivateKeyContext(ctx context.Context, params DeactivateKeyParams, opts ...*RequestOptions) (*DeactivateKeyResponse, error) {
 	var result DeactivateKeyResponse
-	err := c.handleRequest("POST", "/key/deactivate", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "DeactivateKey", method: "POST", path: "/key/deactivate", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -290,8 +137,14 @@ func (c *Client) DeactivateKey(params DeactivateKeyParams, opts ...*RequestOptio
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the checkout response or an error.
 func (c *Client) FloatingCheckout(params FloatingCheckoutParams, opts ...*RequestOptions) (*FloatingCheckoutResponse, error) {
+	return c.FloatingCheckoutContext(context.Background(), params, opts...)
+}
+
+// FloatingCheckoutContext is like FloatingCheckout but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) FloatingCheckoutContext(ctx context.Context, params FloatingCheckoutParams, opts ...*RequestOptions) (*FloatingCheckoutResponse, error) {
 	var result FloatingCheckoutResponse
-	err := c.handleRequest("POST", "/key/checkout", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "FloatingCheckout", method: "POST", path: "/key/checkout", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -300,8 +153,14 @@ func (c *Client) FloatingCheckout(params FloatingCheckoutParams, opts ...*Reques
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the heartbeat response or an error.
 func (c *Client) FloatingHeartbeat(params FloatingHeartbeatParams, opts ...*RequestOptions) (*FloatingHeartbeatResponse, error) {
+	return c.FloatingHeartbeatContext(context.Background(), params, opts...)
+}
+
+// FloatingHeartbeatContext is like FloatingHeartbeat but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) FloatingHeartbeatContext(ctx context.Context, params FloatingHeartbeatParams, opts ...*RequestOptions) (*FloatingHeartbeatResponse, error) {
 	var result FloatingHeartbeatResponse
-	err := c.handleRequest("POST", "/key/heartbeat", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "FloatingHeartbeat", method: "POST", path: "/key/heartbeat", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -310,21 +169,34 @@ func (c *Client) FloatingHeartbeat(params FloatingHeartbeatParams, opts ...*Requ
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the checkin response or an error.
 func (c *Client) FloatingCheckin(params FloatingCheckinParams, opts ...*RequestOptions) (*FloatingCheckinResponse, error) {
+	return c.FloatingCheckinContext(context.Background(), params, opts...)
+}
+
+// FloatingCheckinContext is like FloatingCheckin but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) FloatingCheckinContext(ctx context.Context, params FloatingCheckinParams, opts ...*RequestOptions) (*FloatingCheckinResponse, error) {
 	var result FloatingCheckinResponse
-	err := c.handleRequest("POST", "/key/checkin", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "FloatingCheckin", method: "POST", path: "/key/checkin", params: params}, &result, opts...)
 	return &result, err
 }
 
 // GetKey retrieves detailed information about a specific license key.
 // params: Parameters for fetching the key details.
+// opts: Optional request configurations (e.g. headers or a timeout).
 // Returns the license key details or an error.
-func (c *Client) GetKey(params GetKeyParams) (*GetKeyResponse, error) {
+func (c *Client) GetKey(params GetKeyParams, opts ...*RequestOptions) (*GetKeyResponse, error) {
+	return c.GetKeyContext(context.Background(), params, opts...)
+}
+
+// GetKeyContext is like GetKey but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) GetKeyContext(ctx context.Context, params GetKeyParams, opts ...*RequestOptions) (*GetKeyResponse, error) {
 	var result GetKeyResponse
 	queryParams := map[string]string{
 		"productId":  params.ProductID,
 		"licenseKey": params.LicenseKey,
 	}
-	err := c.handleGetRequest("/key", queryParams, &result)
+	err := c.handleRequest(ctx, &request{operation: "GetKey", method: "GET", path: "/key", query: queryParams, params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -333,8 +205,14 @@ func (c *Client) GetKey(params GetKeyParams) (*GetKeyResponse, error) {
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the block confirmation or an error.
 func (c *Client) BlockKey(params BlockKeyParams, opts ...*RequestOptions) (*BlockKeyResponse, error) {
+	return c.BlockKeyContext(context.Background(), params, opts...)
+}
+
+// BlockKeyContext is like BlockKey but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) BlockKeyContext(ctx context.Context, params BlockKeyParams, opts ...*RequestOptions) (*BlockKeyResponse, error) {
 	var result BlockKeyResponse
-	err := c.handleRequest("POST", "/key/block", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "BlockKey", method: "POST", path: "/key/block", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -343,8 +221,14 @@ func (c *Client) BlockKey(params BlockKeyParams, opts ...*RequestOptions) (*Bloc
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the unblock confirmation or an error.
 func (c *Client) UnblockKey(params UnblockKeyParams, opts ...*RequestOptions) (*UnblockKeyResponse, error) {
+	return c.UnblockKeyContext(context.Background(), params, opts...)
+}
+
+// UnblockKeyContext is like UnblockKey but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) UnblockKeyContext(ctx context.Context, params UnblockKeyParams, opts ...*RequestOptions) (*UnblockKeyResponse, error) {
 	var result UnblockKeyResponse
-	err := c.handleRequest("POST", "/key/unblock", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "UnblockKey", method: "POST", path: "/key/unblock", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -353,15 +237,28 @@ func (c *Client) UnblockKey(params UnblockKeyParams, opts ...*RequestOptions) (*
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the created customer information or an error.
 func (c *Client) CreateCustomer(params CreateCustomerParams, opts ...*RequestOptions) (*CreateCustomerResponse, error) {
+	return c.CreateCustomerContext(context.Background(), params, opts...)
+}
+
+// CreateCustomerContext is like CreateCustomer but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) CreateCustomerContext(ctx context.Context, params CreateCustomerParams, opts ...*RequestOptions) (*CreateCustomerResponse, error) {
 	var result CreateCustomerResponse
-	err := c.handleRequest("POST", "/customer", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "CreateCustomer", method: "POST", path: "/customer", params: params}, &result, opts...)
 	return &result, err
 }
 
 // GetAllCustomers retrieves all customers.
 // params: Optional parameters for pagination and filtering.
+// opts: Optional request configurations (e.g. headers or a timeout).
 // Returns a list of all customers or an error.
-func (c *Client) GetAllCustomers(params GetAllCustomersParams) (*GetAllCustomersResponse, error) {
+func (c *Client) GetAllCustomers(params GetAllCustomersParams, opts ...*RequestOptions) (*GetAllCustomersResponse, error) {
+	return c.GetAllCustomersContext(context.Background(), params, opts...)
+}
+
+// GetAllCustomersContext is like GetAllCustomers but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) GetAllCustomersContext(ctx context.Context, params GetAllCustomersParams, opts ...*RequestOptions) (*GetAllCustomersResponse, error) {
 	var result GetAllCustomersResponse
 	queryParams := make(map[string]string)
 	if params.Page != nil {
@@ -374,19 +271,26 @@ func (c *Client) GetAllCustomers(params GetAllCustomersParams) (*GetAllCustomers
 		queryParams["email"] = *params.Email
 	}
 
-	err := c.handleGetRequest("/customer", queryParams, &result)
+	err := c.handleRequest(ctx, &request{operation: "GetAllCustomers", method: "GET", path: "/customer", query: queryParams, params: params}, &result, opts...)
 	return &result, err
 }
 
 // GetCustomerWithKeys retrieves a customer along with their associated license keys.
 // params: Parameters containing the customer ID.
+// opts: Optional request configurations (e.g. headers or a timeout).
 // Returns the customer information with associated license keys or an error.
-func (c *Client) GetCustomerWithKeys(params GetCustomerWithKeysParams) (*GetCustomerWithKeysResponse, error) {
+func (c *Client) GetCustomerWithKeys(params GetCustomerWithKeysParams, opts ...*RequestOptions) (*GetCustomerWithKeysResponse, error) {
+	return c.GetCustomerWithKeysContext(context.Background(), params, opts...)
+}
+
+// GetCustomerWithKeysContext is like GetCustomerWithKeys but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) GetCustomerWithKeysContext(ctx context.Context, params GetCustomerWithKeysParams, opts ...*RequestOptions) (*GetCustomerWithKeysResponse, error) {
 	var result GetCustomerWithKeysResponse
 	queryParams := map[string]string{
 		"customerId": params.CustomerID,
 	}
-	err := c.handleGetRequest("/customer/keys", queryParams, &result)
+	err := c.handleRequest(ctx, &request{operation: "GetCustomerWithKeys", method: "GET", path: "/customer/keys", query: queryParams, params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -395,8 +299,14 @@ func (c *Client) GetCustomerWithKeys(params GetCustomerWithKeysParams) (*GetCust
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the update confirmation or an error.
 func (c *Client) UpdateCustomer(params UpdateCustomerParams, opts ...*RequestOptions) (*UpdateCustomerResponse, error) {
+	return c.UpdateCustomerContext(context.Background(), params, opts...)
+}
+
+// UpdateCustomerContext is like UpdateCustomer but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) UpdateCustomerContext(ctx context.Context, params UpdateCustomerParams, opts ...*RequestOptions) (*UpdateCustomerResponse, error) {
 	var result UpdateCustomerResponse
-	err := c.handleRequest("PUT", "/customer/by-id", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "UpdateCustomer", method: "PUT", path: "/customer/by-id", params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -405,11 +315,17 @@ func (c *Client) UpdateCustomer(params UpdateCustomerParams, opts ...*RequestOpt
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the deletion confirmation or an error.
 func (c *Client) DeleteCustomer(params DeleteCustomerParams, opts ...*RequestOptions) (*DeleteCustomerResponse, error) {
+	return c.DeleteCustomerContext(context.Background(), params, opts...)
+}
+
+// DeleteCustomerContext is like DeleteCustomer but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) DeleteCustomerContext(ctx context.Context, params DeleteCustomerParams, opts ...*RequestOptions) (*DeleteCustomerResponse, error) {
 	var result DeleteCustomerResponse
 	queryParams := map[string]string{
 		"customerId": params.CustomerID,
 	}
-	err := c.handleDeleteRequest("/customer/by-id", queryParams, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "DeleteCustomer", method: "DELETE", path: "/customer/by-id", query: queryParams, params: params}, &result, opts...)
 	return &result, err
 }
 
@@ -418,19 +334,32 @@ func (c *Client) DeleteCustomer(params DeleteCustomerParams, opts ...*RequestOpt
 // opts: Optional request configurations (e.g. idempotency keys).
 // Returns the status toggle confirmation or an error.
 func (c *Client) ToggleCustomerStatus(params ToggleCustomerStatusParams, opts ...*RequestOptions) (*ToggleCustomerStatusResponse, error) {
+	return c.ToggleCustomerStatusContext(context.Background(), params, opts...)
+}
+
+// ToggleCustomerStatusContext is like ToggleCustomerStatus but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) ToggleCustomerStatusContext(ctx context.Context, params ToggleCustomerStatusParams, opts ...*RequestOptions) (*ToggleCustomerStatusResponse, error) {
 	var result ToggleCustomerStatusResponse
-	err := c.handleRequest("POST", "/customer/disable", params, &result, opts...)
+	err := c.handleRequest(ctx, &request{operation: "ToggleCustomerStatus", method: "POST", path: "/customer/disable", params: params}, &result, opts...)
 	return &result, err
 }
 
 // GetCustomerById retrieves detailed information about a specific customer by ID.
 // params: Parameters containing the customer ID.
+// opts: Optional request configurations (e.g. headers or a timeout).
 // Returns the customer information or an error.
-func (c *Client) GetCustomerById(params GetCustomerByIdParams) (*GetCustomerByIdResponse, error) {
+func (c *Client) GetCustomerById(params GetCustomerByIdParams, opts ...*RequestOptions) (*GetCustomerByIdResponse, error) {
+	return c.GetCustomerByIdContext(context.Background(), params, opts...)
+}
+
+// GetCustomerByIdContext is like GetCustomerById but carries ctx to the underlying HTTP request,
+// so the call is aborted when ctx is canceled or its deadline expires.
+func (c *Client) GetCustomerByIdContext(ctx context.Context, params GetCustomerByIdParams, opts ...*RequestOptions) (*GetCustomerByIdResponse, error) {
 	var result GetCustomerByIdResponse
 	queryParams := map[string]string{
 		"customerId": params.CustomerID,
 	}
-	err := c.handleGetRequest("/customer/by-id", queryParams, &result)
+	err := c.handleRequest(ctx, &request{operation: "GetCustomerById", method: "GET", path: "/customer/by-id", query: queryParams, params: params}, &result, opts...)
 	return &result, err
 }
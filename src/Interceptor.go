@@ -0,0 +1,109 @@
+package keymint
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call describes a single Keymint API call as it travels through the interceptor chain.
+// Interceptors may modify the request fields before calling the next Invoker,
+// and inspect the response fields once it returns.
+type Call struct {
+	// Operation is the name of the Client method making the call (e.g. "ActivateKey").
+	Operation string
+	// Method is the HTTP method.
+	Method string
+	// BaseURL is the API base URL the request is sent to.
+	BaseURL string
+	// Path is the API endpoint path (e.g. "/key/activate").
+	Path string
+	// Query holds the query string parameters of GET and DELETE calls.
+	Query map[string]string
+	// Params is the params struct passed to the Client method.
+	// It is encoded as the JSON request body unless Method is GET or DELETE.
+	Params interface{}
+	// Header holds the request headers, including Authorization and Idempotency-Key.
+	Header http.Header
+
+	// Attempts is the number of HTTP requests sent for the call, including retries.
+	// It is zero if the call was short-circuited before reaching the API.
+	Attempts int
+	// Response is the raw HTTP response, set once the call completed. Its body has already been read.
+	Response *http.Response
+	// ResponseBody is the raw response body, set once the call completed.
+	ResponseBody []byte
+	// Result is a pointer to the response struct the body is decoded into (e.g. *ActivateKeyResponse).
+	Result interface{}
+
+	// requestBytes is the size of the JSON request body sent to the API.
+	requestBytes int
+	// generatedIdempotencyKey is the Idempotency-Key generated by the client, or empty if
+	// the caller supplied one (or the call is a GET).
+	generatedIdempotencyKey string
+}
+
+// Invoker performs a call, either by sending it to the API or by passing it down the interceptor chain.
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor wraps every API call made by a Client. It receives the call and the next
+// Invoker in the chain, and returns the error of the call. An interceptor may modify
+// the call before invoking next, inspect the response and error afterwards, or
+// short-circuit the call by not invoking next, completing it with Call.Respond instead.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+// WithInterceptors appends interceptors to the client's chain.
+// The first interceptor is the outermost one: it sees the call first and the response last.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) {
+		for _, interceptor := range interceptors {
+			if interceptor != nil {
+				c.interceptors = append(c.interceptors, interceptor)
+			}
+		}
+	}
+}
+
+// instrumentation returns the built-in interceptors enabled by the client options.
+// They run outside the interceptors added with WithInterceptors.
+func (c *Client) instrumentation() []Interceptor {
+	var interceptors []Interceptor
+	if c.metrics != nil {
+		interceptors = append(interceptors, c.metricsInterceptor)
+	}
+	if c.tracer != nil {
+		interceptors = append(interceptors, c.traceInterceptor)
+	}
+	if c.logger != nil {
+		interceptors = append(interceptors, c.logInterceptor)
+	}
+	return interceptors
+}
+
+// Respond completes the call without sending it, as if the API had answered with the
+// given HTTP status and JSON body. The body is decoded into Result, and the returned
+// error is the one the Client method would have returned for that response.
+func (call *Call) Respond(status int, body []byte) error {
+	return call.complete(&http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+	}, body)
+}
+
+// complete records the response of the call and decodes it into Result.
+func (call *Call) complete(resp *http.Response, body []byte) error {
+	call.Response = resp
+	call.ResponseBody = body
+	return decodeResponse(resp, body, call.Result)
+}
+
+// intercept runs call through the client's interceptor chain, ending with invoke.
+func (c *Client) intercept(ctx context.Context, call *Call) error {
+	next := Invoker(c.invoke)
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := c.interceptors[i], next
+		next = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, inner)
+		}
+	}
+	return next(ctx, call)
+}
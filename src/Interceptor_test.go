@@ -0,0 +1,74 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestInterceptorsRunInOrder(t *testing.T) {
+	var seen string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get("X-Trail")
+		_, _ = w.Write([]byte(`{"code":0,"message":"ok"}`))
+	}))
+	defer server.Close()
+
+	var trail []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, call *Call, next Invoker) error {
+			trail = append(trail, name+" before")
+			call.Header.Set("X-Trail", call.Header.Get("X-Trail")+name)
+			err := next(ctx, call)
+			trail = append(trail, name+" after")
+			return err
+		}
+	}
+
+	metrics := NewInMemoryMetrics()
+	client, _ := newTestClient(t, server.URL, WithMetrics(metrics), WithInterceptors(record("a"), nil, record("b")))
+	if _, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: "k"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a before", "b before", "b after", "a after"}; !reflect.DeepEqual(trail, want) {
+		t.Errorf("got %v, want %v", trail, want)
+	}
+	if seen != "ab" {
+		t.Errorf("the API received X-Trail %q, want %q", seen, "ab")
+	}
+	if snapshot := metrics.Snapshot(); len(snapshot) != 1 || snapshot[0].Calls != 1 {
+		t.Errorf("got metrics %+v, want one call recorded by the built-in interceptor", snapshot)
+	}
+}
+
+func TestInterceptorShortCircuits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request reached the API")
+	}))
+	defer server.Close()
+
+	cached := func(ctx context.Context, call *Call, next Invoker) error {
+		if call.Operation == "GetKey" {
+			return call.Respond(http.StatusOK, []byte(`{"code":0,"data":{"license":{"key":"cached"}}}`))
+		}
+		return call.Respond(http.StatusForbidden, []byte(`{"message":"License key is blocked","code":3}`))
+	}
+	client, attempts := newTestClient(t, server.URL, WithInterceptors(cached))
+
+	res, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.License.Key != "cached" || *attempts != 0 {
+		t.Errorf("got key %q after %d attempts, want the cached response without attempts", res.Data.License.Key, *attempts)
+	}
+
+	_, err = client.BlockKey(BlockKeyParams{ProductID: "p", LicenseKey: "k"})
+	if !errors.Is(err, ErrKeyBlocked) {
+		t.Errorf("got %v, want the error of the cached response", err)
+	}
+}
@@ -0,0 +1,130 @@
+package keymint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeMetadata decodes the metadata of a license key (e.g. LicenseDetails.Metadata or
+// FloatingCheckoutResponse.Metadata) into a value of type T, typically a struct whose json
+// tags describe your entitlements:
+//
+//	type Entitlements struct {
+//		Seats    int      `json:"seats"`
+//		Edition  string   `json:"edition"`
+//		Features []string `json:"features"`
+//	}
+//
+//	entitlements, err := keymint.DecodeMetadata[Entitlements](res.Data.License.Metadata)
+//
+// Nil metadata decodes to the zero value of T.
+func DecodeMetadata[T any](metadata map[string]interface{}) (T, error) {
+	var v T
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return v, fmt.Errorf("keymint: failed to decode metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("keymint: failed to decode metadata: %w", err)
+	}
+	return v, nil
+}
+
+// EncodeMetadata encodes v, typically a struct decoded by DecodeMetadata, as license key
+// metadata for CreateKeyParams.Metadata. v must encode to a JSON object.
+func EncodeMetadata(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("keymint: failed to encode metadata: %w", err)
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("keymint: failed to encode metadata: %T does not encode to a JSON object", v)
+	}
+	return metadata, nil
+}
+
+// MetadataSchema validates license key metadata, returning nil if it is valid. A
+// *ValidationError return reports each invalid metadata field.
+type MetadataSchema func(metadata map[string]interface{}) error
+
+// MetadataSchemaOf returns a MetadataSchema accepting the metadata that decodes into T
+// without unknown or mistyped fields, and passes the Validate method of T if it has one:
+//
+//	func (e Entitlements) Validate() error {
+//		if e.Seats < 1 {
+//			return &keymint.ValidationError{Fields: []keymint.FieldError{{Field: "seats", Message: "must be positive"}}}
+//		}
+//		return nil
+//	}
+//
+//	client, err := keymint.New(apiKey, "", keymint.WithMetadataSchema(keymint.MetadataSchemaOf[Entitlements]()))
+func MetadataSchemaOf[T any]() MetadataSchema {
+	return func(metadata map[string]interface{}) error {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+
+		var v T
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&v); err != nil {
+			return &ValidationError{Fields: []FieldError{decodeFieldError(err)}}
+		}
+
+		if validator, ok := interface{}(&v).(interface{ Validate() error }); ok {
+			return validator.Validate()
+		}
+		return nil
+	}
+}
+
+// WithMetadataSchema validates the metadata of CreateKey calls against schema before they are
+// sent. Invalid metadata fails the call with an ApiError of kind ErrValidation, listing the
+// invalid fields as "metadata.<field>". Calls without metadata are not checked.
+func WithMetadataSchema(schema MetadataSchema) Option {
+	return func(c *Client) {
+		c.metadataSchema = schema
+	}
+}
+
+// decodeFieldError describes a JSON decoding error as the invalid field it was caused by.
+func decodeFieldError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return FieldError{Field: typeErr.Field, Message: "must be " + describeType(typeErr.Type)}
+	}
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		return FieldError{Field: name, Message: "is not allowed"}
+	}
+	return FieldError{Message: err.Error()}
+}
+
+// describeType describes the JSON value expected for a Go type.
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
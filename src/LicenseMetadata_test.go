@@ -0,0 +1,117 @@
+package keymint
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testEntitlements struct {
+	Seats    int      `json:"seats"`
+	Edition  string   `json:"edition"`
+	Features []string `json:"features,omitempty"`
+	Limits   struct {
+		Projects int `json:"projects"`
+	} `json:"limits"`
+}
+
+func (e testEntitlements) Validate() error {
+	if e.Seats < 1 {
+		return &ValidationError{Fields: []FieldError{{Field: "seats", Message: "must be positive"}}}
+	}
+	return nil
+}
+
+func TestDecodeMetadata(t *testing.T) {
+	got, err := DecodeMetadata[testEntitlements](map[string]interface{}{
+		"seats":    float64(5),
+		"edition":  "pro",
+		"features": []interface{}{"sso"},
+		"limits":   map[string]interface{}{"projects": float64(10)},
+		"other":    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testEntitlements{Seats: 5, Edition: "pro", Features: []string{"sso"}}
+	want.Limits.Projects = 10
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if _, err := DecodeMetadata[testEntitlements](map[string]interface{}{"seats": "five"}); !errors.As(err, &typeErr) {
+		t.Errorf("got error %v for a mistyped field, want a *json.UnmarshalTypeError", err)
+	}
+
+	if got, err := DecodeMetadata[testEntitlements](nil); err != nil || !reflect.DeepEqual(got, testEntitlements{}) {
+		t.Errorf("got %+v, %v for absent metadata, want the zero value", got, err)
+	}
+}
+
+func TestEncodeMetadata(t *testing.T) {
+	metadata, err := EncodeMetadata(testEntitlements{Seats: 2, Edition: "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"seats": float64(2), "edition": "team", "limits": map[string]interface{}{"projects": float64(0)}}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("got %v, want %v", metadata, want)
+	}
+
+	if _, err := EncodeMetadata([]string{"sso"}); err == nil {
+		t.Error("got no error for a value that does not encode to a JSON object")
+	}
+}
+
+func TestMetadataSchemaOf(t *testing.T) {
+	schema := MetadataSchemaOf[testEntitlements]()
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     []FieldError
+	}{
+		{"valid", map[string]interface{}{"seats": 3, "edition": "pro", "limits": map[string]interface{}{"projects": 1}}, nil},
+		{"mistyped", map[string]interface{}{"seats": "three"}, []FieldError{{Field: "seats", Message: "must be an integer"}}},
+		{"mistyped nested", map[string]interface{}{"seats": 3, "limits": map[string]interface{}{"projects": []int{1}}}, []FieldError{{Field: "limits.projects", Message: "must be an integer"}}},
+		{"unknown field", map[string]interface{}{"seats": 3, "plan": "pro"}, []FieldError{{Field: "plan", Message: "is not allowed"}}},
+		{"invalid", map[string]interface{}{"seats": 0}, []FieldError{{Field: "seats", Message: "must be positive"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema(tt.metadata)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("got error %v for valid metadata", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got error %v, want a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tt.want) {
+				t.Errorf("got fields %+v, want %+v", validationErr.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataSchemaSkipsAbsentMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0,"key":"ABCD-EFGH"}`))
+	}))
+	defer server.Close()
+
+	client, attempts := newTestClient(t, server.URL, WithMetadataSchema(MetadataSchemaOf[testEntitlements]()))
+	if _, err := client.CreateKey(CreateKeyParams{ProductID: "p"}); err != nil {
+		t.Fatalf("got error %v for a call without metadata", err)
+	}
+	if *attempts != 1 {
+		t.Errorf("got %d attempts, want 1", *attempts)
+	}
+}
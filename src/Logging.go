@@ -0,0 +1,213 @@
+package keymint
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// WithLogger enables structured logging of every API call to logger.
+//
+// Completed calls are logged at Info level and failed calls at Warn level, with the
+// operation, HTTP method, path, status, latency, request ID and retry count. Retries
+// are logged at Debug level, as are the request params, headers and response body.
+// Secrets are redacted before logging: the Authorization bearer token, license keys,
+// floating session secrets and heartbeat signatures never reach the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// redactedValue replaces secrets that are removed entirely from logs.
+const redactedValue = "[REDACTED]"
+
+// sensitiveFields lists the JSON fields whose values are redacted in logs.
+// License keys are masked so the last characters remain available for support.
+var sensitiveFields = map[string]func(string) string{
+	"licenseKey":    maskLicenseKey,
+	"key":           maskLicenseKey,
+	"sessionSecret": redact,
+	"signature":     redact,
+}
+
+var (
+	licenseKeyQueryRegex = regexp.MustCompile(`(licenseKey=)[^&\s"]+`)
+	bearerTokenRegex     = regexp.MustCompile(`(Bearer )\S+`)
+	// jsonFieldRegex matches the string fields of JSON embedded in free text, such as a
+	// response body quoted in an error message, even when the document is truncated.
+	jsonFieldRegex = regexp.MustCompile(`"(\w+)"(\s*:\s*)"((?:[^"\\]|\\.)*)("?)`)
+)
+
+// logInterceptor logs every call made through the client.
+func (c *Client) logInterceptor(ctx context.Context, call *Call, next Invoker) error {
+	logger := c.logger
+	debug := logger.Enabled(ctx, slog.LevelDebug)
+
+	if debug {
+		attrs := []slog.Attr{
+			slog.String("operation", call.Operation),
+			slog.String("method", call.Method),
+			slog.String("path", call.Path),
+		}
+		if len(call.Query) > 0 {
+			attrs = append(attrs, slog.Any("query", redactQuery(call.Query)))
+		}
+		attrs = append(attrs,
+			slog.Any("headers", redactHeader(call.Header)),
+			slog.String("params", redactParams(call.Params)),
+		)
+		logger.LogAttrs(ctx, slog.LevelDebug, "keymint request", attrs...)
+	}
+
+	start := time.Now()
+	err := next(ctx, call)
+
+	attrs := []slog.Attr{
+		slog.String("operation", call.Operation),
+		slog.String("method", call.Method),
+		slog.String("path", call.Path),
+		slog.Duration("latency", time.Since(start)),
+		slog.Int("retries", max(call.Attempts-1, 0)),
+	}
+	if call.Response != nil {
+		attrs = append(attrs, slog.Int("status", call.Response.StatusCode))
+		if id := requestID(call.Response.Header); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+	}
+	if debug && call.ResponseBody != nil {
+		attrs = append(attrs, slog.String("response", string(redactJSON(call.ResponseBody))))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", redactString(err.Error())))
+		logger.LogAttrs(ctx, slog.LevelWarn, "keymint request failed", attrs...)
+		return err
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "keymint request completed", attrs...)
+	return nil
+}
+
+// logRetry logs a retried attempt of call at Debug level.
+func (c *Client) logRetry(ctx context.Context, call *Call, resp *http.Response, err error, delay time.Duration) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", call.Operation),
+		slog.Int("attempt", call.Attempts),
+		slog.Duration("delay", delay),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", redactString(err.Error())))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "keymint request retry", attrs...)
+}
+
+// maskLicenseKey hides all but the last 4 characters of a license key.
+func maskLicenseKey(key string) string {
+	if len(key) <= 8 {
+		return redactedValue
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
+
+// redact hides a secret entirely.
+func redact(string) string {
+	return redactedValue
+}
+
+// redactHeader returns a copy of header with the Authorization token removed.
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted.Get("Authorization") != "" {
+		redacted.Set("Authorization", "Bearer "+redactedValue)
+	}
+	return redacted
+}
+
+// redactQuery returns a copy of query with sensitive parameters masked.
+func redactQuery(query map[string]string) map[string]string {
+	if query == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(query))
+	for key, value := range query {
+		if mask, ok := sensitiveFields[key]; ok && value != "" {
+			value = mask(value)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// redactParams encodes params as JSON with sensitive fields masked.
+func redactParams(params interface{}) string {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	return string(redactJSON(data))
+}
+
+// redactJSON masks sensitive fields anywhere in a JSON document.
+// Documents that cannot be parsed are dropped rather than logged verbatim.
+func redactJSON(data []byte) []byte {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return []byte(redactedValue)
+	}
+
+	redacted, err := json.Marshal(redactValue(document))
+	if err != nil {
+		return []byte(redactedValue)
+	}
+	return redacted
+}
+
+// redactValue walks a decoded JSON value and masks sensitive string fields.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if str, ok := field.(string); ok && str != "" {
+				if mask, ok := sensitiveFields[key]; ok {
+					v[key] = mask(str)
+					continue
+				}
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// redactString masks license keys in URLs, bearer tokens and the sensitive fields of
+// JSON documents found in free text, such as error messages quoting a response body.
+func redactString(s string) string {
+	s = licenseKeyQueryRegex.ReplaceAllString(s, "${1}"+redactedValue)
+	s = bearerTokenRegex.ReplaceAllString(s, "${1}"+redactedValue)
+	return jsonFieldRegex.ReplaceAllStringFunc(s, func(field string) string {
+		match := jsonFieldRegex.FindStringSubmatch(field)
+		mask, ok := sensitiveFields[match[1]]
+		if !ok || match[3] == "" {
+			return field
+		}
+		return `"` + match[1] + `"` + match[2] + `"` + mask(match[3]) + match[4]
+	})
+}
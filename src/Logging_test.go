@@ -0,0 +1,57 @@
+package keymint
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggingRedactsErrorBodies(t *testing.T) {
+	const key = "ABCD-EFGH-IJKL-MNOP"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid","licenseKey":"` + key + `","sessionSecret":"s3cr3t"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, _ := newTestClient(t, server.URL, WithLogger(logger))
+	_, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: key})
+	if err == nil || !strings.Contains(err.Error(), key) {
+		t.Fatalf("got error %v, want the unredacted body in the returned error", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "level=WARN") {
+		t.Fatalf("the failure was not logged at WARN:\n%s", output)
+	}
+	for _, secret := range []string{key, "s3cr3t", "test_key"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("the log output contains %q:\n%s", secret, output)
+		}
+	}
+	if !strings.Contains(output, "MNOP") {
+		t.Errorf("the log output does not keep the last characters of the license key:\n%s", output)
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`GET /key?licenseKey=ABCD-EFGH&productId=p`, `GET /key?licenseKey=[REDACTED]&productId=p`},
+		{`Authorization: Bearer abc.def`, `Authorization: Bearer [REDACTED]`},
+		{`API error: {"key": "ABCD-EFGH-IJKL", "productId":"p"}`, `API error: {"key": "**********IJKL", "productId":"p"}`},
+		{`API error: {"signature":"a\"b","licenseKey":"ABCD-EFGH-IJK`, `API error: {"signature":"[REDACTED]","licenseKey":"*********-IJK`},
+		{`API error: {"key":""}`, `API error: {"key":""}`},
+	}
+	for _, tt := range tests {
+		if got := redactString(tt.in); got != tt.want {
+			t.Errorf("redactString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
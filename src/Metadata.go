@@ -0,0 +1,66 @@
+package keymint
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseMetadata describes the HTTP response of an API call.
+// Capture it by setting RequestOptions.Response:
+//
+//	var meta keymint.ResponseMetadata
+//	res, err := client.ActivateKey(params, &keymint.RequestOptions{Response: &meta})
+//	log.Printf("request ID: %s", meta.RequestID)
+type ResponseMetadata struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Header holds the response headers.
+	Header http.Header
+	// RequestID is the request ID assigned by the API, useful for support tickets.
+	RequestID string
+	// RateLimit is the rate-limit state reported by the API, or nil if the response carried none.
+	RateLimit *RateLimitInfo
+	// ServerTime is the server time from the Date header, or the zero time if absent.
+	ServerTime time.Time
+}
+
+// RateLimitInfo is the rate-limit state reported by the API in response headers.
+type RateLimitInfo struct {
+	// Limit is the number of requests allowed in the current window, or 0 if not reported.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is when the current window ends, or the zero time if not reported.
+	Reset time.Time
+}
+
+// requestIDHeaders lists the response headers the API may use to carry a request ID.
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Correlation-Id"}
+
+// requestID returns the request ID carried by a response header, or an empty string.
+func requestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// newResponseMetadata extracts the metadata of resp.
+func newResponseMetadata(resp *http.Response) ResponseMetadata {
+	meta := ResponseMetadata{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		RequestID:  requestID(resp.Header),
+	}
+
+	if info, ok := parseRateLimitInfo(resp.Header, time.Now()); ok {
+		meta.RateLimit = &info
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		meta.ServerTime = date
+	}
+
+	return meta
+}
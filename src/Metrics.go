@@ -0,0 +1,298 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallEvent describes a completed API call, as reported to a MetricsObserver.
+type CallEvent struct {
+	// Operation is the name of the Client method (e.g. "ActivateKey").
+	Operation string
+	// Endpoint is the API endpoint path (e.g. "/key/activate").
+	Endpoint string
+	// Method is the HTTP method.
+	Method string
+	// Status is the HTTP status code, or 0 if no response was received.
+	Status int
+	// ErrorCode is the ApiError.Code of a failed call, or 0 if the call succeeded.
+	ErrorCode int
+	// Err is the error returned by the call, or nil.
+	Err error
+	// Duration is the total time spent in the call, including retries.
+	Duration time.Duration
+	// Retries is the number of retried HTTP requests.
+	Retries int
+	// RequestBytes is the size of the JSON request body.
+	RequestBytes int
+	// ResponseBytes is the size of the response body.
+	ResponseBytes int
+}
+
+// MetricsObserver receives an event for every API call made by a Client.
+// Implementations must be safe for concurrent use.
+type MetricsObserver interface {
+	ObserveCall(ctx context.Context, event CallEvent)
+}
+
+// WithMetrics reports every API call to observer.
+func WithMetrics(observer MetricsObserver) Option {
+	return func(c *Client) {
+		c.metrics = observer
+	}
+}
+
+// metricsInterceptor reports every call to the client's MetricsObserver.
+func (c *Client) metricsInterceptor(ctx context.Context, call *Call, next Invoker) error {
+	start := time.Now()
+	err := next(ctx, call)
+
+	event := CallEvent{
+		Operation:     call.Operation,
+		Endpoint:      call.Path,
+		Method:        call.Method,
+		Err:           err,
+		Duration:      time.Since(start),
+		Retries:       max(call.Attempts-1, 0),
+		RequestBytes:  call.requestBytes,
+		ResponseBytes: len(call.ResponseBody),
+	}
+	if call.Response != nil {
+		event.Status = call.Response.StatusCode
+	}
+
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		event.ErrorCode = apiErr.Code
+	}
+
+	c.metrics.ObserveCall(ctx, event)
+	return err
+}
+
+// DefaultLatencyBuckets are the latency histogram bucket upper bounds used by
+// NewInMemoryMetrics when none are given.
+var DefaultLatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// InMemoryMetrics is a MetricsObserver keeping counters and latency histograms in memory,
+// per operation. Read them with Snapshot, or expose them in the Prometheus text format
+// through ServeHTTP or WriteTo.
+type InMemoryMetrics struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	series  map[string]*OperationMetrics
+}
+
+// OperationMetrics holds the metrics recorded for a single operation.
+type OperationMetrics struct {
+	// Operation is the name of the Client method (e.g. "ActivateKey").
+	Operation string
+	// Method is the HTTP method.
+	Method string
+	// Calls is the number of calls.
+	Calls int64
+	// Errors is the number of failed calls.
+	Errors int64
+	// Retries is the number of retried HTTP requests.
+	Retries int64
+	// RequestBytes is the total size of the request bodies.
+	RequestBytes int64
+	// ResponseBytes is the total size of the response bodies.
+	ResponseBytes int64
+	// StatusCodes counts calls per HTTP status code (0 when no response was received).
+	StatusCodes map[int]int64
+	// ErrorCodes counts failed calls per ApiError.Code.
+	ErrorCodes map[int]int64
+	// Latency is the histogram of call durations.
+	Latency Histogram
+}
+
+// Histogram is a cumulative latency histogram.
+type Histogram struct {
+	// Buckets holds the bucket upper bounds, in increasing order.
+	Buckets []time.Duration
+	// Counts holds, for each bucket, the number of observations less than or equal to its upper bound.
+	Counts []int64
+	// Count is the total number of observations.
+	Count int64
+	// Sum is the sum of all observations.
+	Sum time.Duration
+}
+
+// NewInMemoryMetrics creates an InMemoryMetrics using the given latency bucket upper bounds,
+// or DefaultLatencyBuckets if none are given.
+func NewInMemoryMetrics(buckets ...time.Duration) *InMemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &InMemoryMetrics{
+		buckets: sorted,
+		series:  make(map[string]*OperationMetrics),
+	}
+}
+
+// ObserveCall implements MetricsObserver.
+func (m *InMemoryMetrics) ObserveCall(_ context.Context, event CallEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := event.Operation + " " + event.Method
+	series, ok := m.series[key]
+	if !ok {
+		series = &OperationMetrics{
+			Operation:   event.Operation,
+			Method:      event.Method,
+			StatusCodes: make(map[int]int64),
+			ErrorCodes:  make(map[int]int64),
+			Latency: Histogram{
+				Buckets: m.buckets,
+				Counts:  make([]int64, len(m.buckets)),
+			},
+		}
+		m.series[key] = series
+	}
+
+	series.Calls++
+	series.Retries += int64(event.Retries)
+	series.RequestBytes += int64(event.RequestBytes)
+	series.ResponseBytes += int64(event.ResponseBytes)
+	series.StatusCodes[event.Status]++
+	if event.Err != nil {
+		series.Errors++
+		series.ErrorCodes[event.ErrorCode]++
+	}
+
+	series.Latency.Count++
+	series.Latency.Sum += event.Duration
+	for i, bound := range series.Latency.Buckets {
+		if event.Duration <= bound {
+			series.Latency.Counts[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the metrics recorded so far, sorted by operation.
+func (m *InMemoryMetrics) Snapshot() []OperationMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]OperationMetrics, 0, len(m.series))
+	for _, series := range m.series {
+		s := *series
+		s.StatusCodes = make(map[int]int64, len(series.StatusCodes))
+		for code, count := range series.StatusCodes {
+			s.StatusCodes[code] = count
+		}
+		s.ErrorCodes = make(map[int]int64, len(series.ErrorCodes))
+		for code, count := range series.ErrorCodes {
+			s.ErrorCodes[code] = count
+		}
+		s.Latency.Counts = append([]int64(nil), series.Latency.Counts...)
+		snapshot = append(snapshot, s)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Operation != snapshot[j].Operation {
+			return snapshot[i].Operation < snapshot[j].Operation
+		}
+		return snapshot[i].Method < snapshot[j].Method
+	})
+	return snapshot
+}
+
+// Reset discards all recorded metrics.
+func (m *InMemoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series = make(map[string]*OperationMetrics)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (m *InMemoryMetrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	snapshot := m.Snapshot()
+
+	writeCounter := func(name, help string, value func(OperationMetrics) int64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, s := range snapshot {
+			fmt.Fprintf(&b, "%s{%s} %d\n", name, labels(s), value(s))
+		}
+	}
+
+	writeCounter("keymint_calls_total", "Number of Keymint API calls.", func(s OperationMetrics) int64 { return s.Calls })
+	writeCounter("keymint_errors_total", "Number of failed Keymint API calls.", func(s OperationMetrics) int64 { return s.Errors })
+	writeCounter("keymint_retries_total", "Number of retried Keymint API requests.", func(s OperationMetrics) int64 { return s.Retries })
+	writeCounter("keymint_request_bytes_total", "Total size of Keymint API request bodies.", func(s OperationMetrics) int64 { return s.RequestBytes })
+	writeCounter("keymint_response_bytes_total", "Total size of Keymint API response bodies.", func(s OperationMetrics) int64 { return s.ResponseBytes })
+
+	fmt.Fprintf(&b, "# HELP keymint_responses_total Number of Keymint API calls per HTTP status.\n# TYPE keymint_responses_total counter\n")
+	for _, s := range snapshot {
+		for _, status := range sortedKeys(s.StatusCodes) {
+			fmt.Fprintf(&b, "keymint_responses_total{%s,status=\"%d\"} %d\n", labels(s), status, s.StatusCodes[status])
+		}
+	}
+	fmt.Fprintf(&b, "# HELP keymint_error_codes_total Number of failed Keymint API calls per API error code.\n# TYPE keymint_error_codes_total counter\n")
+	for _, s := range snapshot {
+		for _, code := range sortedKeys(s.ErrorCodes) {
+			fmt.Fprintf(&b, "keymint_error_codes_total{%s,code=\"%d\"} %d\n", labels(s), code, s.ErrorCodes[code])
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP keymint_call_duration_seconds Duration of Keymint API calls, including retries.\n# TYPE keymint_call_duration_seconds histogram\n")
+	for _, s := range snapshot {
+		for i, bound := range s.Latency.Buckets {
+			fmt.Fprintf(&b, "keymint_call_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels(s), bound.Seconds(), s.Latency.Counts[i])
+		}
+		fmt.Fprintf(&b, "keymint_call_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(s), s.Latency.Count)
+		fmt.Fprintf(&b, "keymint_call_duration_seconds_sum{%s} %g\n", labels(s), s.Latency.Sum.Seconds())
+		fmt.Fprintf(&b, "keymint_call_duration_seconds_count{%s} %d\n", labels(s), s.Latency.Count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format, so that an
+// InMemoryMetrics can be mounted as a scrape endpoint.
+func (m *InMemoryMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// labels formats the Prometheus labels identifying an operation.
+func labels(s OperationMetrics) string {
+	return fmt.Sprintf("operation=%q,method=%q", s.Operation, s.Method)
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys(m map[int]int64) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,132 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingObserver is a MetricsObserver recording the events it receives.
+type recordingObserver struct {
+	events []CallEvent
+}
+
+func (o *recordingObserver) ObserveCall(_ context.Context, event CallEvent) {
+	o.events = append(o.events, event)
+}
+
+func TestMetricsCallEvents(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/key/block":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"License key is blocked","code":3}`))
+		case requests.Add(1) <= 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"unavailable"}`))
+		default:
+			_, _ = w.Write([]byte(`{"code":0}`))
+		}
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client, _ := newTestClient(t, server.URL, WithMetrics(observer))
+	if _, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	_, blockErr := client.BlockKey(BlockKeyParams{ProductID: "p", LicenseKey: "k"})
+
+	if len(observer.events) != 2 {
+		t.Fatalf("got %d events, want 2", len(observer.events))
+	}
+	get, block := observer.events[0], observer.events[1]
+	if get.Operation != "GetKey" || get.Endpoint != "/key" || get.Method != "GET" || get.Status != 200 || get.Retries != 2 || get.Err != nil || get.ResponseBytes != len(`{"code":0}`) {
+		t.Errorf("got GetKey event %+v, want a success after 2 retries", get)
+	}
+	if block.Status != 403 || block.ErrorCode != 3 || block.Retries != 0 || !errors.Is(block.Err, blockErr) || block.RequestBytes == 0 {
+		t.Errorf("got BlockKey event %+v, want a failure with code 3", block)
+	}
+}
+
+func TestInMemoryMetrics(t *testing.T) {
+	metrics := NewInMemoryMetrics(100*time.Millisecond, 10*time.Millisecond)
+	ctx := context.Background()
+	metrics.ObserveCall(ctx, CallEvent{Operation: "GetKey", Method: "GET", Status: 200, Duration: 5 * time.Millisecond, Retries: 1, RequestBytes: 0, ResponseBytes: 120})
+	metrics.ObserveCall(ctx, CallEvent{Operation: "GetKey", Method: "GET", Status: 404, ErrorCode: 2, Err: errors.New("not found"), Duration: 50 * time.Millisecond, ResponseBytes: 40})
+	metrics.ObserveCall(ctx, CallEvent{Operation: "ActivateKey", Method: "POST", Status: 0, ErrorCode: -1, Err: errors.New("network"), Duration: time.Second, Retries: 2, RequestBytes: 64})
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Operation != "ActivateKey" || snapshot[1].Operation != "GetKey" {
+		t.Fatalf("got %+v, want ActivateKey and GetKey, sorted", snapshot)
+	}
+	getKey := snapshot[1]
+	if getKey.Calls != 2 || getKey.Errors != 1 || getKey.Retries != 1 || getKey.ResponseBytes != 160 ||
+		getKey.StatusCodes[200] != 1 || getKey.StatusCodes[404] != 1 || getKey.ErrorCodes[2] != 1 {
+		t.Errorf("got GetKey metrics %+v", getKey)
+	}
+	if got := getKey.Latency; got.Count != 2 || got.Sum != 55*time.Millisecond || got.Counts[0] != 1 || got.Counts[1] != 2 {
+		t.Errorf("got GetKey latency %+v, want one call under 10ms and two under 100ms", got)
+	}
+
+	var out strings.Builder
+	if _, err := metrics.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP keymint_calls_total Number of Keymint API calls.
+# TYPE keymint_calls_total counter
+keymint_calls_total{operation="ActivateKey",method="POST"} 1
+keymint_calls_total{operation="GetKey",method="GET"} 2
+# HELP keymint_errors_total Number of failed Keymint API calls.
+# TYPE keymint_errors_total counter
+keymint_errors_total{operation="ActivateKey",method="POST"} 1
+keymint_errors_total{operation="GetKey",method="GET"} 1
+# HELP keymint_retries_total Number of retried Keymint API requests.
+# TYPE keymint_retries_total counter
+keymint_retries_total{operation="ActivateKey",method="POST"} 2
+keymint_retries_total{operation="GetKey",method="GET"} 1
+# HELP keymint_request_bytes_total Total size of Keymint API request bodies.
+# TYPE keymint_request_bytes_total counter
+keymint_request_bytes_total{operation="ActivateKey",method="POST"} 64
+keymint_request_bytes_total{operation="GetKey",method="GET"} 0
+# HELP keymint_response_bytes_total Total size of Keymint API response bodies.
+# TYPE keymint_response_bytes_total counter
+keymint_response_bytes_total{operation="ActivateKey",method="POST"} 0
+keymint_response_bytes_total{operation="GetKey",method="GET"} 160
+# HELP keymint_responses_total Number of Keymint API calls per HTTP status.
+# TYPE keymint_responses_total counter
+keymint_responses_total{operation="ActivateKey",method="POST",status="0"} 1
+keymint_responses_total{operation="GetKey",method="GET",status="200"} 1
+keymint_responses_total{operation="GetKey",method="GET",status="404"} 1
+# HELP keymint_error_codes_total Number of failed Keymint API calls per API error code.
+# TYPE keymint_error_codes_total counter
+keymint_error_codes_total{operation="ActivateKey",method="POST",code="-1"} 1
+keymint_error_codes_total{operation="GetKey",method="GET",code="2"} 1
+# HELP keymint_call_duration_seconds Duration of Keymint API calls, including retries.
+# TYPE keymint_call_duration_seconds histogram
+keymint_call_duration_seconds_bucket{operation="ActivateKey",method="POST",le="0.01"} 0
+keymint_call_duration_seconds_bucket{operation="ActivateKey",method="POST",le="0.1"} 0
+keymint_call_duration_seconds_bucket{operation="ActivateKey",method="POST",le="+Inf"} 1
+keymint_call_duration_seconds_sum{operation="ActivateKey",method="POST"} 1
+keymint_call_duration_seconds_count{operation="ActivateKey",method="POST"} 1
+keymint_call_duration_seconds_bucket{operation="GetKey",method="GET",le="0.01"} 1
+keymint_call_duration_seconds_bucket{operation="GetKey",method="GET",le="0.1"} 2
+keymint_call_duration_seconds_bucket{operation="GetKey",method="GET",le="+Inf"} 2
+keymint_call_duration_seconds_sum{operation="GetKey",method="GET"} 0.055
+keymint_call_duration_seconds_count{operation="GetKey",method="GET"} 2
+`
+	if out.String() != want {
+		t.Errorf("WriteTo wrote:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	metrics.Reset()
+	if snapshot := metrics.Snapshot(); len(snapshot) != 0 {
+		t.Errorf("got %+v after Reset, want no metrics", snapshot)
+	}
+}
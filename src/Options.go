@@ -0,0 +1,80 @@
+package keymint
+
+import (
+	"net/http"
+	"time"
+)
+
+// defaultUserAgent is sent with every request unless overridden with WithUserAgent.
+const defaultUserAgent = "keymint-go"
+
+// Option configures a Client created with New.
+type Option func(*Client)
+
+// WithHTTPClient sets the http.Client used to send requests, allowing a custom
+// transport, proxy or TLS configuration. The client is copied, never modified.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithTimeout sets the overall timeout of each HTTP request (defaults to 30 seconds).
+// A zero duration disables the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = &timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithDefaultHeader adds a header sent with every request.
+// It cannot override the Authorization or Content-Type headers.
+func WithDefaultHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithBaseURL sets the API base URL, taking precedence over the baseURL argument of New.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
+// requestHeader builds the headers of a request from the client-wide defaults
+// and the per-request options.
+func (c *Client) requestHeader(options *RequestOptions, idempotencyKey string) http.Header {
+	header := c.headers.Clone()
+
+	if c.userAgent != "" {
+		header.Set("User-Agent", c.userAgent)
+	}
+	for key, value := range options.Headers {
+		header.Set(key, value)
+	}
+
+	apiKey := c.apiKey
+	if options.APIKey != "" {
+		apiKey = options.APIKey
+	}
+	header.Set("Authorization", "Bearer "+apiKey)
+	header.Set("Content-Type", "application/json")
+
+	if idempotencyKey != "" {
+		header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	return header
+}
@@ -0,0 +1,194 @@
+package keymint
+
+import (
+	"context"
+	"iter"
+)
+
+// Page is a page of items returned by a list endpoint.
+type Page[T any] struct {
+	// Items holds the items of the page.
+	Items []T
+	// Meta is the pagination metadata of the page, or nil if the API returned none.
+	Meta *PaginationMeta
+
+	// current, if set, is called by Paginate when the page, or its error, is about to be
+	// yielded, in the goroutine running the loop.
+	current func()
+}
+
+// PageFetcher fetches a page of a list endpoint. page counts from 1.
+type PageFetcher[T any] func(ctx context.Context, page int) (Page[T], error)
+
+// PaginateOption configures the iterators returned by Paginate.
+type PaginateOption func(*paginateConfig)
+
+// paginateConfig holds the PaginateOption settings.
+type paginateConfig struct {
+	prefetch       bool
+	requestOptions []*RequestOptions
+}
+
+// newPaginateConfig returns the settings of opts.
+func newPaginateConfig(opts []PaginateOption) paginateConfig {
+	var config paginateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithPrefetch fetches the next page concurrently while the items of the current page are consumed.
+func WithPrefetch() PaginateOption {
+	return func(config *paginateConfig) {
+		config.prefetch = true
+	}
+}
+
+// WithRequestOptions sends opts with every page request of an iterator such as
+// Client.AllCustomers, e.g. to list the customers of a tenant with its own API key:
+//
+//	client.AllCustomers(ctx, params, keymint.WithRequestOptions(&keymint.RequestOptions{APIKey: tenantKey}))
+//
+// Paginate itself ignores it: pass the options to the PageFetcher instead (see CustomerPages).
+func WithRequestOptions(opts ...*RequestOptions) PaginateOption {
+	return func(config *paginateConfig) {
+		config.requestOptions = append(config.requestOptions, opts...)
+	}
+}
+
+// Paginate returns an iterator over the items of a list endpoint. Pages are fetched lazily
+// with fetch, starting from page 1, until a page is empty or its metadata reports it is
+// the last one.
+//
+// The iteration stops after yielding an error, either from fetch or from ctx when it is
+// cancelled between pages. Breaking out of the loop cancels a prefetch in flight.
+func Paginate[T any](ctx context.Context, fetch PageFetcher[T], opts ...PaginateOption) iter.Seq2[T, error] {
+	config := newPaginateConfig(opts)
+
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			page Page[T]
+			err  error
+		}
+		var prefetched chan result
+
+		for page := 1; ; page++ {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			var current result
+			if prefetched != nil {
+				current = <-prefetched
+				prefetched = nil
+			} else {
+				current.page, current.err = fetch(ctx, page)
+			}
+			if current.page.current != nil {
+				current.page.current()
+			}
+			if current.err != nil {
+				var zero T
+				yield(zero, current.err)
+				return
+			}
+
+			last := isLastPage(current.page)
+			if config.prefetch && !last {
+				prefetched = make(chan result, 1)
+				go func(page int) {
+					var next result
+					next.page, next.err = fetch(ctx, page)
+					prefetched <- next
+				}(page + 1)
+			}
+
+			for _, item := range current.page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if last {
+				return
+			}
+		}
+	}
+}
+
+// isLastPage reports whether no page follows page.
+func isLastPage[T any](page Page[T]) bool {
+	return len(page.Items) == 0 || (page.Meta != nil && page.Meta.Page >= page.Meta.TotalPages)
+}
+
+// CustomerPages returns a PageFetcher listing the customers matching params with api,
+// sending opts with every page request. params.Page is the first page fetched (1 if nil),
+// and params.Limit the page size.
+//
+// Each page request records its response metadata in a copy of opts, so that a prefetch
+// does not overwrite it concurrently: when iterated by Paginate, the Response of opts holds
+// the metadata of the page whose items, or error, the loop is consuming.
+func CustomerPages(api KeymintAPI, params GetAllCustomersParams, opts ...*RequestOptions) PageFetcher[Customer] {
+	first := 1
+	if params.Page != nil {
+		first = *params.Page
+	}
+
+	return func(ctx context.Context, page int) (Page[Customer], error) {
+		pageParams := params
+		pageParams.Page = Ptr(first + page - 1)
+		pageOpts, current := pageRequestOptions(opts)
+		res, err := api.GetAllCustomersContext(ctx, pageParams, pageOpts...)
+		if err != nil {
+			return Page[Customer]{current: current}, err
+		}
+		return Page[Customer]{Items: res.Data, Meta: res.Meta, current: current}, nil
+	}
+}
+
+// pageRequestOptions returns shallow copies of opts for one page request, each recording
+// the response metadata in its own ResponseMetadata, and a function copying the recorded
+// metadata to the Response of opts.
+func pageRequestOptions(opts []*RequestOptions) ([]*RequestOptions, func()) {
+	copies := make([]*RequestOptions, len(opts))
+	for i, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		c := *opt
+		if opt.Response != nil {
+			c.Response = new(ResponseMetadata)
+		}
+		copies[i] = &c
+	}
+
+	return copies, func() {
+		for i, opt := range opts {
+			// A zero StatusCode means no response was received.
+			if opt != nil && opt.Response != nil && copies[i].Response.StatusCode != 0 {
+				*opt.Response = *copies[i].Response
+			}
+		}
+	}
+}
+
+// AllCustomers returns an iterator over the customers matching params, fetching the pages
+// lazily as the loop consumes them:
+//
+//	for customer, err := range client.AllCustomers(ctx, keymint.GetAllCustomersParams{Limit: keymint.Ptr(100)}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(customer.Email)
+//	}
+//
+// params.Page is the first page fetched, and params.Limit the page size. Pass
+// WithRequestOptions to send RequestOptions (e.g. a per-tenant API key) with every page request.
+func (c *Client) AllCustomers(ctx context.Context, params GetAllCustomersParams, opts ...PaginateOption) iter.Seq2[Customer, error] {
+	return Paginate(ctx, CustomerPages(c, params, newPaginateConfig(opts).requestOptions...), opts...)
+}
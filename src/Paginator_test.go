@@ -0,0 +1,185 @@
+package keymint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// numberPages returns a PageFetcher over the numbers from 1 to total, by pages of size numbers.
+func numberPages(total, size int) PageFetcher[int] {
+	return func(ctx context.Context, page int) (Page[int], error) {
+		var items []int
+		for n := (page-1)*size + 1; n <= total && n <= page*size; n++ {
+			items = append(items, n)
+		}
+		return Page[int]{Items: items, Meta: &PaginationMeta{Page: page, TotalPages: (total + size - 1) / size}}, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	for _, opts := range [][]PaginateOption{nil, {WithPrefetch()}} {
+		var got []int
+		for n, err := range Paginate(context.Background(), numberPages(7, 3), opts...) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, n)
+		}
+		if want := []int{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPaginateStopsOnEmptyPageAndError(t *testing.T) {
+	calls := 0
+	withoutMeta := func(ctx context.Context, page int) (Page[int], error) {
+		calls++
+		if page > 2 {
+			return Page[int]{}, nil
+		}
+		return Page[int]{Items: []int{page}}, nil
+	}
+	count := 0
+	for _, err := range Paginate(context.Background(), withoutMeta) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 2 || calls != 3 {
+		t.Errorf("got %d items in %d calls, want 2 items in 3 calls", count, calls)
+	}
+
+	failure := errors.New("page failed")
+	failing := func(ctx context.Context, page int) (Page[int], error) {
+		if page == 2 {
+			return Page[int]{}, failure
+		}
+		return Page[int]{Items: []int{page}}, nil
+	}
+	var errs []error
+	for _, err := range Paginate(context.Background(), failing) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], failure) {
+		t.Errorf("got %v, want an item then the page error", errs)
+	}
+}
+
+func TestPaginateBreakCancelsPrefetch(t *testing.T) {
+	prefetchDone := make(chan error, 1)
+	fetch := func(ctx context.Context, page int) (Page[int], error) {
+		if page == 1 {
+			return Page[int]{Items: []int{1, 2}, Meta: &PaginationMeta{Page: 1, TotalPages: 2}}, nil
+		}
+		<-ctx.Done()
+		prefetchDone <- ctx.Err()
+		return Page[int]{}, ctx.Err()
+	}
+
+	for range Paginate(context.Background(), fetch, WithPrefetch()) {
+		break
+	}
+
+	select {
+	case err := <-prefetchDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want the prefetch to be canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the prefetch goroutine was not canceled after breaking out of the loop")
+	}
+}
+
+func TestPaginateStopsWhenContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errs []error
+	for _, err := range Paginate(ctx, numberPages(10, 1)) {
+		errs = append(errs, err)
+		cancel()
+	}
+	if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], context.Canceled) {
+		t.Errorf("got %v, want an item then the context error", errs)
+	}
+}
+
+func TestAllCustomersSendsRequestOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tenant_key" {
+			http.Error(w, `{"message":"Invalid API key","code":13}`, http.StatusUnauthorized)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		_ = json.NewEncoder(w).Encode(GetAllCustomersResponse{
+			Status: true,
+			Data:   []Customer{{ID: "cust_" + strconv.Itoa(page)}},
+			Meta:   &PaginationMeta{Page: page, TotalPages: 3},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(t, "http://127.0.0.1:1")
+	options := WithRequestOptions(&RequestOptions{APIKey: "tenant_key", BaseURL: server.URL})
+
+	var ids []string
+	for customer, err := range client.AllCustomers(context.Background(), GetAllCustomersParams{}, options, WithPrefetch()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, customer.ID)
+	}
+	if want := []string{"cust_1", "cust_2", "cust_3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
+
+func TestAllCustomersResponseMetadataWithPrefetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("X-Request-Id", "req_"+strconv.Itoa(page))
+		if page == 3 {
+			http.Error(w, `{"message":"Bad request","code":1}`, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GetAllCustomersResponse{
+			Status: true,
+			Data:   []Customer{{ID: "cust_" + strconv.Itoa(page)}, {ID: "cust_" + strconv.Itoa(page)}},
+			Meta:   &PaginationMeta{Page: page, TotalPages: 3},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(t, server.URL)
+	var meta ResponseMetadata
+	options := WithRequestOptions(&RequestOptions{Response: &meta})
+
+	var ids []string
+	var lastErr error
+	for customer, err := range client.AllCustomers(context.Background(), GetAllCustomersParams{}, options, WithPrefetch()) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		// The page holding the customer, not the prefetched one.
+		if want := "req_" + customer.ID[len("cust_"):]; meta.RequestID != want {
+			t.Errorf("got request ID %q while consuming %s, want %q", meta.RequestID, customer.ID, want)
+		}
+		ids = append(ids, customer.ID)
+	}
+
+	if len(ids) != 4 || !errors.Is(lastErr, ErrBadRequest) {
+		t.Fatalf("got customers %v and error %v, want 4 customers then ErrBadRequest", ids, lastErr)
+	}
+	if meta.RequestID != "req_3" || meta.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %s %d after the failed page, want req_3 400", meta.RequestID, meta.StatusCode)
+	}
+}
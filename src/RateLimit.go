@@ -0,0 +1,204 @@
+package keymint
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a client-side token bucket.
+type RateLimit struct {
+	// Rate is the number of requests allowed per second. Values of 0 or less disable the limit.
+	Rate float64
+	// Burst is the number of requests that can be sent at once. Values below 1 are treated as 1.
+	Burst int
+}
+
+// WithRateLimit limits the rate of all requests sent by the client.
+// Requests block, honoring their context, until the limiter allows them.
+func WithRateLimit(limit RateLimit) Option {
+	return func(c *Client) {
+		c.limiter.global = nil
+		if limit.Rate > 0 {
+			c.limiter.global = newTokenBucket(limit)
+		}
+	}
+}
+
+// WithEndpointRateLimit limits the rate of the requests sent by one Client method,
+// identified by its name (e.g. "CreateKey"). It applies in addition to WithRateLimit.
+func WithEndpointRateLimit(operation string, limit RateLimit) Option {
+	return func(c *Client) {
+		if limit.Rate <= 0 {
+			delete(c.limiter.endpoints, operation)
+			return
+		}
+		if c.limiter.endpoints == nil {
+			c.limiter.endpoints = make(map[string]*tokenBucket)
+		}
+		c.limiter.endpoints[operation] = newTokenBucket(limit)
+	}
+}
+
+// rateLimiter holds the token buckets configured on a client.
+// The zero value allows every request.
+type rateLimiter struct {
+	global    *tokenBucket
+	endpoints map[string]*tokenBucket
+}
+
+// buckets returns the token buckets that apply to operation.
+func (l *rateLimiter) buckets(operation string) []*tokenBucket {
+	var buckets []*tokenBucket
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+	if bucket, ok := l.endpoints[operation]; ok {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// wait blocks until every bucket that applies to operation allows a request, or until ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, operation string) error {
+	for _, bucket := range l.buckets(operation) {
+		if err := bucket.wait(ctx); err != nil {
+			return &ApiError{
+				Message: fmt.Sprintf("request canceled while waiting for the rate limiter: %v", err),
+				Code:    -1,
+				err:     err,
+			}
+		}
+	}
+	return nil
+}
+
+// observe adapts the buckets that apply to operation to the rate-limit headers of a response:
+// when the server reports the limit is exhausted, or answers 429, requests are held back
+// until the reported reset time.
+func (l *rateLimiter) observe(operation string, resp *http.Response) {
+	buckets := l.buckets(operation)
+	if len(buckets) == 0 || resp == nil {
+		return
+	}
+
+	now := time.Now()
+	var until time.Time
+
+	if info, ok := parseRateLimitInfo(resp.Header, now); ok && info.Remaining == 0 && info.Reset.After(now) {
+		until = info.Reset
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok && now.Add(retryAfter).After(until) {
+			until = now.Add(retryAfter)
+		}
+	}
+
+	if until.IsZero() {
+		return
+	}
+	for _, bucket := range buckets {
+		bucket.pause(until)
+	}
+}
+
+// tokenBucket is a token bucket rate limiter safe for concurrent use.
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// newTokenBucket creates a full token bucket.
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := math.Max(float64(limit.Burst), 1)
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token from the bucket, blocking until one is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		delay := b.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token if one is available and returns 0,
+// or returns how long to wait before trying again.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// pause holds back every request until the given time.
+func (b *tokenBucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+		b.tokens = 0
+	}
+}
+
+// parseRateLimitInfo reads the X-RateLimit-* (or IETF draft RateLimit-*) response headers.
+// The reset value is accepted either as seconds until the reset or as a Unix timestamp.
+// Returns false if the response carries no rate-limit headers.
+func parseRateLimitInfo(header http.Header, now time.Time) (RateLimitInfo, bool) {
+	get := func(name string) string {
+		if value := header.Get("X-RateLimit-" + name); value != "" {
+			return value
+		}
+		return header.Get("RateLimit-" + name)
+	}
+
+	remaining, err := strconv.Atoi(get("Remaining"))
+	if err != nil {
+		return RateLimitInfo{}, false
+	}
+
+	info := RateLimitInfo{Remaining: remaining}
+	if limit, err := strconv.Atoi(get("Limit")); err == nil {
+		info.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(get("Reset"), 10, 64); err == nil {
+		// Values this large can only be Unix timestamps (more than 30 years of delay otherwise).
+		if reset > 1_000_000_000 {
+			info.Reset = time.Unix(reset, 0)
+		} else {
+			info.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return info, true
+}
@@ -0,0 +1,99 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRefill(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 10, Burst: 2})
+	now := b.last
+
+	for i := 0; i < 2; i++ {
+		if delay := b.reserve(now); delay != 0 {
+			t.Fatalf("burst request %d: delay %v", i+1, delay)
+		}
+	}
+	if delay := b.reserve(now); delay != 100*time.Millisecond {
+		t.Fatalf("delay = %v, want 100ms", delay)
+	}
+	if delay := b.reserve(now.Add(100 * time.Millisecond)); delay != 0 {
+		t.Fatalf("after refilling one token: delay %v", delay)
+	}
+	// Tokens never accumulate beyond the burst.
+	later := now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if delay := b.reserve(later); delay != 0 {
+			t.Fatalf("after an hour, request %d: delay %v", i+1, delay)
+		}
+	}
+	if delay := b.reserve(later); delay == 0 {
+		t.Fatal("the bucket refilled beyond its burst")
+	}
+}
+
+func TestTokenBucketPause(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 10, Burst: 5})
+	now := b.last
+	until := now.Add(2 * time.Second)
+
+	b.pause(until)
+	b.pause(now.Add(time.Second)) // an earlier pause does not shorten it
+	if delay := b.reserve(now); delay != 2*time.Second {
+		t.Fatalf("delay = %v, want 2s", delay)
+	}
+	if delay := b.reserve(until); delay != 0 {
+		t.Fatalf("after the pause: delay %v", delay)
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	limiter := rateLimiter{endpoints: map[string]*tokenBucket{"ActivateKey": newTokenBucket(RateLimit{Rate: 100, Burst: 10})}}
+	bucket := limiter.endpoints["ActivateKey"]
+
+	limiter.observe("GetKey", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"5"}}})
+	if !bucket.pausedUntil.IsZero() {
+		t.Fatal("a response of another operation paused the bucket")
+	}
+
+	limiter.observe("ActivateKey", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"5"}}})
+	if wait := time.Until(bucket.pausedUntil); wait < 4*time.Second || wait > 5*time.Second {
+		t.Fatalf("paused for %v, want 5s", wait)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := limiter.wait(ctx, "ActivateKey")
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait() = %v, want an ApiError wrapping context.DeadlineExceeded", err)
+	}
+}
+
+func TestParseRateLimitInfo(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   RateLimitInfo
+		ok     bool
+	}{
+		{"none", http.Header{}, RateLimitInfo{}, false},
+		{"x-ratelimit with delay", http.Header{"X-Ratelimit-Limit": {"100"}, "X-Ratelimit-Remaining": {"7"}, "X-Ratelimit-Reset": {"30"}}, RateLimitInfo{Limit: 100, Remaining: 7, Reset: now.Add(30 * time.Second)}, true},
+		{"ietf with timestamp", http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"1700000060"}}, RateLimitInfo{Remaining: 0, Reset: time.Unix(1_700_000_060, 0)}, true},
+		{"malformed remaining", http.Header{"X-Ratelimit-Remaining": {"many"}}, RateLimitInfo{}, false},
+		{"malformed reset", http.Header{"X-Ratelimit-Remaining": {"3"}, "X-Ratelimit-Reset": {"soon"}}, RateLimitInfo{Remaining: 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRateLimitInfo(tt.header, now)
+			if ok != tt.ok || got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("parseRateLimitInfo() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package keymint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// request describes a single call to the Keymint API.
+type request struct {
+	// operation is the name of the Client method making the call (e.g. "CreateKey").
+	operation string
+	// method is the HTTP method.
+	method string
+	// path is the API endpoint path (e.g. "/key/activate").
+	path string
+	// query holds the optional query string parameters.
+	query map[string]string
+	// params is the params struct passed to the Client method.
+	// It is encoded as the JSON request body unless method is GET or DELETE.
+	params interface{}
+}
+
+// handleRequest runs a request through the shared request pipeline used by every Client method.
+// It applies the per-request options, runs the interceptor chain, encodes the query string
+// and JSON body, sends the request with retries and decodes the response into result.
+// ctx: Context controlling cancellation and deadlines of the request.
+// req: Description of the API call.
+// result: Pointer to the result struct to unmarshal response into.
+// opts: Optional request configurations (e.g. idempotency keys).
+// Returns an error if the request fails or the API returns an error.
+func (c *Client) handleRequest(ctx context.Context, req *request, result interface{}, opts ...*RequestOptions) error {
+	options := requestOptions(opts)
+
+	// Mutating requests without a caller-supplied idempotency key get a generated one,
+	// reused if the request is sent again after the connection could not be established.
+	idempotencyKey := options.IdempotencyKey
+	generatedIdempotencyKey := ""
+	if idempotencyKey == "" && req.method != "GET" {
+		idempotencyKey = uuid.NewString()
+		generatedIdempotencyKey = idempotencyKey
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	baseURL := c.endpoints.preferred()
+	if options.BaseURL != "" {
+		baseURL = options.BaseURL
+	}
+
+	call := &Call{
+		Operation: req.operation,
+		Method:    req.method,
+		BaseURL:   baseURL,
+		Path:      req.path,
+		Query:     req.query,
+		Params:    req.params,
+		Header:    c.requestHeader(options, idempotencyKey),
+		Result:    result,
+
+		generatedIdempotencyKey: generatedIdempotencyKey,
+	}
+
+	err := c.intercept(ctx, call)
+	if options.Response != nil && call.Response != nil {
+		*options.Response = newResponseMetadata(call.Response)
+	}
+	return err
+}
+
+// invoke is the innermost Invoker of the interceptor chain: it validates the params,
+// sends the call to the API and decodes the response into call.Result.
+func (c *Client) invoke(ctx context.Context, call *Call) error {
+	if err := c.validate(call.Params); err != nil {
+		return err
+	}
+
+	var body []byte
+	if call.Method != "GET" && call.Method != "DELETE" {
+		jsonData, err := json.Marshal(call.Params)
+		if err != nil {
+			return &ApiError{
+				Message: fmt.Sprintf("failed to marshal request: %v", err),
+				Code:    -1,
+				err:     err,
+			}
+		}
+		body = jsonData
+	}
+	call.requestBytes = len(body)
+
+	resp, respBody, err := c.send(ctx, call, body)
+	if err != nil {
+		call.Response = resp
+		return err
+	}
+
+	return call.complete(resp, respBody)
+}
+
+// requestOptions returns the first non-nil RequestOptions, or empty options if there is none.
+func requestOptions(opts []*RequestOptions) *RequestOptions {
+	for _, opt := range opts {
+		if opt != nil {
+			return opt
+		}
+	}
+	return &RequestOptions{}
+}
+
+// encodeQuery encodes query parameters into a query string, including the leading "?".
+// Returns an empty string if there are no parameters.
+func encodeQuery(queryParams map[string]string) string {
+	if len(queryParams) == 0 {
+		return ""
+	}
+
+	q := url.Values{}
+	for key, value := range queryParams {
+		q.Add(key, value)
+	}
+	return "?" + q.Encode()
+}
+
+// send performs the HTTP request of call, retrying it according to the client's retry policy.
+// A request that may have reached the API is only retried if call.resendable allows it;
+// one that never left the client (the connection could not be established) always may be.
+// The number of attempts made is recorded in call.Attempts.
+// Returns the final response (its body already read and closed) and the response body.
+func (c *Client) send(ctx context.Context, call *Call, body []byte) (*http.Response, []byte, error) {
+	policy := c.retryPolicy
+	resendable := call.resendable()
+
+	for attempt := 1; ; attempt++ {
+		call.Attempts = attempt
+		resp, respBody, retryable, err := c.sendAttempt(ctx, call, body)
+		if !retryable || attempt >= policy.MaxAttempts || (!resendable && !notSent(err)) {
+			return resp, respBody, err
+		}
+
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if policy.MaxRetryAfter > 0 && retryAfter > policy.MaxRetryAfter {
+					return resp, respBody, err
+				}
+				delay = retryAfter
+			}
+		}
+
+		c.logRetry(ctx, call, resp, err, delay)
+
+		if sleepContext(ctx, delay) != nil {
+			return resp, respBody, err
+		}
+	}
+}
+
+// sendAttempt performs one attempt of the HTTP request of call. The request is sent to
+// each of the client's base URLs in turn until one answers without a connection error
+// or a 5xx status; call.BaseURL is updated to the base URL that answered. A call that is
+// not resendable only moves on to the next base URL if the request was not sent, and
+// none does after a permanent transport error.
+// Returns the response, the response body, whether the attempt is worth
+// retrying, and an error if the attempt failed.
+func (c *Client) sendAttempt(ctx context.Context, call *Call, body []byte) (*http.Response, []byte, bool, error) {
+	baseURLs := c.endpoints.order(call.BaseURL, time.Now())
+	pathAndQuery := call.Path + encodeQuery(call.Query)
+	resendable := call.resendable()
+
+	var (
+		resp      *http.Response
+		respBody  []byte
+		retryable bool
+		err       error
+	)
+	for _, baseURL := range baseURLs {
+		if err := c.limiter.wait(ctx, call.Operation); err != nil {
+			return nil, nil, false, err
+		}
+
+		if err := c.breaker.allow(); err != nil {
+			return nil, nil, false, err
+		}
+
+		resp, respBody, retryable, err = c.sendOnce(ctx, call.Method, baseURL+pathAndQuery, body, call.Header)
+		c.breaker.record(resp, err, ctx.Err() != nil)
+		c.limiter.observe(call.Operation, resp)
+
+		if ctx.Err() != nil {
+			break
+		}
+		if err == nil && resp.StatusCode < 500 {
+			c.endpoints.healthy(baseURL, time.Now())
+			call.BaseURL = baseURL
+			break
+		}
+		if (!resendable && !notSent(err)) || (err != nil && !retryable) {
+			break
+		}
+	}
+
+	return resp, respBody, retryable, err
+}
+
+// sendOnce performs a single HTTP request attempt.
+// Returns the response, the response body, whether the attempt is worth
+// retrying, and an error if the attempt failed.
+func (c *Client) sendOnce(ctx context.Context, method, rawURL string, body []byte, header http.Header) (*http.Response, []byte, bool, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bodyReader)
+	if err != nil {
+		return nil, nil, false, &ApiError{
+			Message: fmt.Sprintf("failed to create request: %v", err),
+			Code:    -1,
+			err:     err,
+		}
+	}
+
+	req.Header = header.Clone()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		apiErr := transportError(ctx, err)
+		return nil, nil, errors.Is(apiErr, ErrNetwork), apiErr
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, ctx.Err() == nil, &ApiError{
+			Message:   fmt.Sprintf("failed to read response: %v", err),
+			Code:      -1,
+			Status:    &resp.StatusCode,
+			RequestID: requestID(resp.Header),
+			err:       err,
+		}
+	}
+
+	return resp, respBody, isRetryableStatus(resp.StatusCode), nil
+}
+
+// decodeResponse converts an error response into an ApiError carrying the request ID
+// of the response, or unmarshals a successful response body into result.
+func decodeResponse(resp *http.Response, body []byte, result interface{}) error {
+	err := decodeBody(resp, body, result)
+
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) && apiErr.RequestID == "" {
+		apiErr.RequestID = requestID(resp.Header)
+	}
+	return err
+}
+
+// decodeBody converts an error response into an ApiError,
+// or unmarshals a successful response body into result.
+func decodeBody(resp *http.Response, body []byte, result interface{}) error {
+	if resp.StatusCode >= 400 {
+		var envelope responseEnvelope
+		if err := json.Unmarshal(body, &envelope); err == nil && envelope.Message != "" {
+			code := 0
+			if envelope.Code != nil {
+				code = *envelope.Code
+			}
+			return &ApiError{
+				Message: envelope.Message,
+				Code:    code,
+				Status:  &resp.StatusCode,
+			}
+		}
+		return &ApiError{
+			Message: fmt.Sprintf("API error: %s", string(body)),
+			Code:    -1,
+			Status:  &resp.StatusCode,
+		}
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return &ApiError{
+			Message: fmt.Sprintf("failed to unmarshal response: %v", err),
+			Code:    -1,
+			Status:  &resp.StatusCode,
+			err:     err,
+		}
+	}
+
+	return checkEnvelope(resp, body)
+}
+
+// responseEnvelope holds the fields shared by every API response body.
+type responseEnvelope struct {
+	Code    *int            `json:"code"`
+	Status  json.RawMessage `json:"status"`
+	Message string          `json:"message"`
+}
+
+// checkEnvelope reports a successful HTTP response whose body signals a failure,
+// either with a non-zero "code" or with "status": false, as an ApiError.
+func checkEnvelope(resp *http.Response, body []byte) error {
+	var envelope responseEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+
+	failed := false
+	code := 0
+	if envelope.Code != nil && *envelope.Code != 0 {
+		failed = true
+		code = *envelope.Code
+	}
+	if string(envelope.Status) == "false" {
+		failed = true
+	}
+	if !failed {
+		return nil
+	}
+
+	message := envelope.Message
+	if message == "" {
+		message = fmt.Sprintf("API returned an unsuccessful response: %s", string(body))
+	}
+
+	return &ApiError{
+		Message: message,
+		Code:    code,
+		Status:  &resp.StatusCode,
+	}
+}
+
+// transportError converts a failed http.Client.Do call into an ApiError.
+// If the request context was canceled or its deadline expired, the message
+// reports the context error so callers can tell it apart from network failures;
+// errors.Is(err, context.Canceled) and errors.Is(err, context.DeadlineExceeded) work either way.
+// Errors that retrying cannot fix, such as a cassette replay miss, are not classified as
+// ErrNetwork: see permanentError.
+func transportError(ctx context.Context, err error) *ApiError {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return &ApiError{
+			Message: fmt.Sprintf("request canceled: %v", ctxErr),
+			Code:    -1,
+			err:     err,
+		}
+	}
+	var permanent permanentError
+	if errors.As(err, &permanent) && permanent.Permanent() {
+		return &ApiError{
+			Message: fmt.Sprintf("request failed: %v", err),
+			Code:    -1,
+			err:     err,
+		}
+	}
+	return &ApiError{
+		Message: fmt.Sprintf("request failed: %v", err),
+		Code:    -1,
+		kind:    ErrNetwork,
+		err:     err,
+	}
+}
+
+// permanentError is implemented by the errors of an http.RoundTripper that fail the same
+// way however often the request is sent (e.g. a cassette with no recorded interaction for
+// it). When Permanent returns true, the call fails without being retried or failed over.
+type permanentError interface {
+	error
+	Permanent() bool
+}
@@ -0,0 +1,155 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+//
+// A request is retried when the HTTP call fails at the transport level or the API
+// responds with 429 or a 5xx status. Only safe requests are retried: GET requests, and
+// mutating requests when the caller supplied RequestOptions.IdempotencyKey. A mutating
+// request sent with the key the client generates is not resent once it may have reached
+// the API, since that could apply it twice (e.g. create two license keys). FloatingHeartbeat
+// and FloatingCheckin are never sent twice, since their nonce can only be used once. Any
+// request is retried if the connection could not be established, as it was never sent.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the delay grows by after each attempt.
+	Multiplier float64
+	// Jitter is the fraction (0 to 1) of each delay that is randomized.
+	Jitter float64
+	// MaxRetryAfter is the longest Retry-After delay the client is willing to wait.
+	// If the server asks for a longer delay, the error is returned instead.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.5,
+	MaxRetryAfter:  time.Minute,
+}
+
+// WithRetryPolicy sets the retry policy of the client.
+// Use RetryPolicy{MaxAttempts: 1} to disable retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// backoff returns the delay before the given retry (1 for the first retry).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	delay -= delay * jitter * rand.Float64()
+
+	return time.Duration(delay)
+}
+
+// nonceOperations lists the operations signed with a single-use floating session nonce:
+// a request that may have reached the API must never be sent again.
+var nonceOperations = map[string]bool{
+	"FloatingHeartbeat": true,
+	"FloatingCheckin":   true,
+}
+
+// resendable reports whether call may be sent again after a request that may have reached the API.
+func (call *Call) resendable() bool {
+	switch {
+	case nonceOperations[call.Operation]:
+		return false
+	case call.Method == "GET":
+		return true
+	}
+
+	key := call.Header.Get("Idempotency-Key")
+	return key != "" && key != call.generatedIdempotencyKey
+}
+
+// notSent reports whether err shows the request never left the client because the
+// connection could not be established, so that sending it again cannot apply it twice.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isRetryableStatus reports whether an HTTP status code is worth retrying.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+// It returns false if the header is missing or malformed.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until ctx is done.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
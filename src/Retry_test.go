@@ -0,0 +1,177 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries is a retry policy without noticeable delays.
+var fastRetries = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+// newTestClient returns a client for baseURL with fast retries, and a pointer to the number of
+// attempts made by the last call.
+func newTestClient(t *testing.T, baseURL string, opts ...Option) (*Client, *int) {
+	t.Helper()
+	attempts := new(int)
+	opts = append([]Option{
+		WithRetryPolicy(fastRetries),
+		WithInterceptors(func(ctx context.Context, call *Call, next Invoker) error {
+			err := next(ctx, call)
+			*attempts = call.Attempts
+			return err
+		}),
+	}, opts...)
+	client, err := New("test_key", baseURL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, attempts
+}
+
+func TestSendResendsOnlySafeCalls(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, `{"message":"unavailable","code":-1}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, attempts := newTestClient(t, server.URL)
+	heartbeat := FloatingHeartbeatParams{ProductID: "p", LicenseKey: "k", SessionID: "s", Timestamp: Nonce{Value: "n"}, Signature: GenerateSessionSignature("s", "n", "secret")}
+
+	tests := []struct {
+		name     string
+		call     func() error
+		attempts int
+	}{
+		{"get", func() error {
+			_, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: "k"})
+			return err
+		}, 3},
+		{"create key", func() error {
+			_, err := client.CreateKey(CreateKeyParams{ProductID: "p"})
+			return err
+		}, 1},
+		{"create key with caller key", func() error {
+			_, err := client.CreateKey(CreateKeyParams{ProductID: "p"}, &RequestOptions{IdempotencyKey: "key"})
+			return err
+		}, 3},
+		{"generated key", func() error {
+			_, err := client.UpdateCustomer(UpdateCustomerParams{CustomerID: "c"})
+			return err
+		}, 1},
+		{"caller key", func() error {
+			_, err := client.UpdateCustomer(UpdateCustomerParams{CustomerID: "c"}, &RequestOptions{IdempotencyKey: "key"})
+			return err
+		}, 3},
+		{"heartbeat", func() error {
+			_, err := client.FloatingHeartbeat(heartbeat)
+			return err
+		}, 1},
+		{"heartbeat with caller key", func() error {
+			_, err := client.FloatingHeartbeat(heartbeat, &RequestOptions{IdempotencyKey: "key"})
+			return err
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			if err := tt.call(); err == nil {
+				t.Fatal("expected an error")
+			}
+			if *attempts != tt.attempts || int(requests.Load()) != tt.attempts {
+				t.Errorf("got %d attempts and %d requests, want %d", *attempts, requests.Load(), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestSendRetriesUnsentCalls(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client, attempts := newTestClient(t, server.URL)
+	_, err := client.FloatingCheckin(FloatingCheckinParams{ProductID: "p", LicenseKey: "k", SessionID: "s", Timestamp: Nonce{Value: "n"}, Signature: GenerateSessionSignature("s", "n", "secret")})
+	if !IsRetryable(err) {
+		t.Fatalf("got %v, want a network error", err)
+	}
+	if *attempts != 3 {
+		t.Errorf("got %d attempts, want 3", *attempts)
+	}
+}
+
+// permanentTransportError is a transport error that retrying cannot fix.
+type permanentTransportError struct{}
+
+func (permanentTransportError) Error() string   { return "no recorded interaction" }
+func (permanentTransportError) Permanent() bool { return true }
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSendDoesNotRetryPermanentErrors(t *testing.T) {
+	transport := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, permanentTransportError{}
+	})
+
+	client, attempts := newTestClient(t, "http://primary", WithHTTPClient(&http.Client{Transport: transport}))
+	_, err := client.GetKey(GetKeyParams{ProductID: "p", LicenseKey: "k"})
+	if !errors.As(err, new(permanentTransportError)) {
+		t.Fatalf("got %v, want the transport error", err)
+	}
+	if IsRetryable(err) {
+		t.Errorf("got a retryable error, want a permanent one")
+	}
+	if *attempts != 1 {
+		t.Errorf("got %d attempts, want 1", *attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		if got := policy.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.backoff(1); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("backoff(1) with jitter = %v, want between 50ms and 100ms", got)
+		}
+	}
+}
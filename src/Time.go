@@ -0,0 +1,158 @@
+package keymint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TimeFormat is the layout the API uses for timestamps: ISO 8601 in UTC with millisecond precision.
+const TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// timeLayouts lists the layouts accepted when decoding a Time, most common first. Date-times
+// separated by a space instead of a T are accepted too.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Time is a timestamp exchanged with the API. It embeds time.Time, so all of its methods
+// are available, and marshals to and from the API's ISO 8601 strings.
+//
+// A null or empty value decodes to the zero Time, and the zero Time encodes to null.
+// Use the omitzero tag option to omit it instead.
+type Time struct {
+	time.Time
+}
+
+// NewTime returns t as a Time.
+func NewTime(t time.Time) Time {
+	return Time{Time: t}
+}
+
+// ParseTime parses an ISO 8601 timestamp, with or without a time zone (UTC is assumed), or a
+// date. Common variants are accepted: "2024-01-15 10:00:00", "2024-01-15T10:00:00+0000",
+// "2024-01-15T10:00:00+00" or "2024-01-15T10:00Z". An empty value returns the zero Time.
+func ParseTime(value string) (Time, error) {
+	if value == "" {
+		return Time{}, nil
+	}
+	// Accept a space instead of the T separating the date from the time.
+	normalized := value
+	if len(value) > 10 && value[10] == ' ' {
+		normalized = value[:10] + "T" + value[11:]
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return Time{Time: t}, nil
+		}
+	}
+	return Time{}, fmt.Errorf("keymint: invalid time %q: expected an ISO 8601 timestamp or date", value)
+}
+
+// String formats t with TimeFormat, or returns an empty string for the zero Time.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeFormat)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("keymint: invalid time %s: expected a string", data)
+	}
+	parsed, err := ParseTime(value)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, used when a Time is encoded as a query parameter or map key.
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Time) UnmarshalText(data []byte) error {
+	parsed, err := ParseTime(string(data))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// expiredAt reports whether a resource expiring at expiresAt has expired at now.
+// The zero Time never expires.
+func expiredAt(expiresAt Time, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt.Time)
+}
+
+// IsExpired reports whether the license key has expired at now. A key without an expiration date never expires.
+func (l LicenseDetails) IsExpired(now time.Time) bool {
+	return expiredAt(l.ExpirationDate, now)
+}
+
+// TimeUntilExpiry returns the time left until the license key expires, negative if it has
+// expired. ok is false if the key has no expiration date.
+func (l LicenseDetails) TimeUntilExpiry() (remaining time.Duration, ok bool) {
+	return time.Until(l.ExpirationDate.Time), !l.ExpirationDate.IsZero()
+}
+
+// IsExpired reports whether the license key has expired at now. A key without an expiration date never expires.
+func (k CustomerLicenseKey) IsExpired(now time.Time) bool {
+	return expiredAt(k.ExpirationDate, now)
+}
+
+// TimeUntilExpiry returns the time left until the license key expires, negative if it has
+// expired. ok is false if the key has no expiration date.
+func (k CustomerLicenseKey) TimeUntilExpiry() (remaining time.Duration, ok bool) {
+	return time.Until(k.ExpirationDate.Time), !k.ExpirationDate.IsZero()
+}
+
+// IsExpired reports whether the floating session has expired at now, unless extended by a heartbeat.
+func (r FloatingCheckoutResponse) IsExpired(now time.Time) bool {
+	return expiredAt(r.ExpiresAt, now)
+}
+
+// TimeUntilExpiry returns the time left to send a heartbeat before the floating session
+// expires, negative if it has expired. ok is false if the response has no expiry time.
+func (r FloatingCheckoutResponse) TimeUntilExpiry() (remaining time.Duration, ok bool) {
+	return time.Until(r.ExpiresAt.Time), !r.ExpiresAt.IsZero()
+}
+
+// IsExpired reports whether the floating session has expired at now, unless extended by another heartbeat.
+func (r FloatingHeartbeatResponse) IsExpired(now time.Time) bool {
+	return expiredAt(r.ExpiresAt, now)
+}
+
+// TimeUntilExpiry returns the time left to send the next heartbeat before the floating
+// session expires, negative if it has expired. ok is false if the response has no expiry time.
+func (r FloatingHeartbeatResponse) TimeUntilExpiry() (remaining time.Duration, ok bool) {
+	return time.Until(r.ExpiresAt.Time), !r.ExpiresAt.IsZero()
+}
@@ -0,0 +1,121 @@
+package keymint
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	accepted := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-01-15T10:00:00.000Z", want},
+		{"2024-01-15T10:00:00Z", want},
+		{"2024-01-15T10:00:00.123456Z", want.Add(123456 * time.Microsecond)},
+		{"2024-01-15T12:00:00+02:00", want},
+		{"2024-01-15T10:00:00+0000", want},
+		{"2024-01-15T05:00:00-0500", want},
+		{"2024-01-15T10:00:00+00", want},
+		{"2024-01-15T10:00:00", want},
+		{"2024-01-15T10:00Z", want},
+		{"2024-01-15T10:00", want},
+		{"2024-01-15 10:00:00", want},
+		{"2024-01-15 10:00:00.5", want.Add(500 * time.Millisecond)},
+		{"2024-01-15 10:00:00Z", want},
+		{"2024-01-15 10:00:00+00:00", want},
+		{"2024-01-15 10:00:00+0000", want},
+		{"2024-01-15", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"", time.Time{}},
+	}
+	for _, test := range accepted {
+		got, err := ParseTime(test.value)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", test.value, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", test.value, got.Time, test.want)
+		}
+	}
+
+	rejected := []string{
+		"2024-01-15T10:00:00+00:0",
+		"2024-01-15T10:00:00 +0000",
+		"2024-01-15T25:00:00Z",
+		"2024-01-15X10:00:00Z",
+		"2024-01-15  10:00:00",
+		"2024-1-15",
+		"15/01/2024",
+		"1705312800",
+		"yesterday",
+	}
+	for _, value := range rejected {
+		if got, err := ParseTime(value); err == nil {
+			t.Errorf("ParseTime(%q) = %v, want an error", value, got.Time)
+		}
+	}
+}
+
+func TestTimeJSON(t *testing.T) {
+	var decoded struct {
+		ExpiresAt Time  `json:"expiresAt"`
+		RevokedAt Time  `json:"revokedAt"`
+		CreatedAt *Time `json:"createdAt"`
+	}
+	data := `{"expiresAt":"2024-01-15 10:00:00+0000","revokedAt":null,"createdAt":""}`
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.ExpiresAt.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("got expiresAt %v", decoded.ExpiresAt.Time)
+	}
+	if !decoded.RevokedAt.IsZero() || !decoded.CreatedAt.IsZero() {
+		t.Errorf("got revokedAt %v and createdAt %v, want zero times", decoded.RevokedAt.Time, decoded.CreatedAt.Time)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"expiresAt":"2024-01-15T10:00:00.000Z","revokedAt":null,"createdAt":null}`; string(encoded) != want {
+		t.Errorf("got %s, want %s", encoded, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"expiresAt":1705312800}`), &decoded); err == nil {
+		t.Error("decoded a number, want an error")
+	}
+}
+
+func TestTimeUntilExpiry(t *testing.T) {
+	expiry := NewTime(time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name  string
+		until func() (time.Duration, bool)
+		want  bool
+	}{
+		{"license", LicenseDetails{ExpirationDate: expiry}.TimeUntilExpiry, true},
+		{"license without expiry", LicenseDetails{}.TimeUntilExpiry, false},
+		{"customer key", CustomerLicenseKey{ExpirationDate: expiry}.TimeUntilExpiry, true},
+		{"customer key without expiry", CustomerLicenseKey{}.TimeUntilExpiry, false},
+		{"checkout", FloatingCheckoutResponse{ExpiresAt: expiry}.TimeUntilExpiry, true},
+		{"checkout without expiry", FloatingCheckoutResponse{}.TimeUntilExpiry, false},
+		{"heartbeat", FloatingHeartbeatResponse{ExpiresAt: expiry}.TimeUntilExpiry, true},
+		{"heartbeat without expiry", FloatingHeartbeatResponse{}.TimeUntilExpiry, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, ok := tt.until()
+			if ok != tt.want {
+				t.Fatalf("got ok %v, want %v", ok, tt.want)
+			}
+			if ok && (remaining <= 59*time.Minute || remaining > time.Hour) {
+				t.Errorf("got %v remaining, want about an hour", remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// Attribute is a key/value pair attached to a tracing span.
+type Attribute struct {
+	// Key is the attribute name (e.g. "keymint.operation").
+	Key string
+	// Value is the attribute value, a string, int or bool.
+	Value interface{}
+}
+
+// Tracer creates spans for API calls. It is a small subset of the OpenTelemetry
+// tracing API, so the SDK does not depend on OpenTelemetry; adapting an OpenTelemetry
+// tracer and propagator takes a few lines (see the README).
+type Tracer interface {
+	// Start starts a span as a child of the span carried by ctx,
+	// and returns a context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+	// Inject writes the trace context carried by ctx into the headers of an
+	// outgoing request (e.g. the W3C traceparent and tracestate headers).
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a single traced operation started by a Tracer.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+	// RecordError records err on the span and marks the span as failed.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Span attribute keys set by the client.
+const (
+	AttributeOperation  = "keymint.operation"
+	AttributeEndpoint   = "keymint.endpoint"
+	AttributeProductID  = "keymint.product_id"
+	AttributeErrorCode  = "keymint.error_code"
+	AttributeAttempts   = "keymint.attempts"
+	AttributeHTTPMethod = "http.request.method"
+	AttributeHTTPStatus = "http.response.status_code"
+)
+
+// WithTracer enables tracing: every Client method call creates a span named
+// "keymint.<Operation>" (e.g. "keymint.ActivateKey"), and the trace context is
+// propagated to the API through the request headers.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// traceInterceptor wraps every call in a span.
+func (c *Client) traceInterceptor(ctx context.Context, call *Call, next Invoker) error {
+	attrs := []Attribute{
+		{Key: AttributeOperation, Value: call.Operation},
+		{Key: AttributeEndpoint, Value: call.Path},
+		{Key: AttributeHTTPMethod, Value: call.Method},
+	}
+	if productID := productIDOf(call); productID != "" {
+		attrs = append(attrs, Attribute{Key: AttributeProductID, Value: productID})
+	}
+
+	ctx, span := c.tracer.Start(ctx, "keymint."+call.Operation, attrs...)
+	defer span.End()
+
+	c.tracer.Inject(ctx, call.Header)
+
+	err := next(ctx, call)
+
+	attrs = []Attribute{{Key: AttributeAttempts, Value: call.Attempts}}
+	if call.Response != nil {
+		attrs = append(attrs, Attribute{Key: AttributeHTTPStatus, Value: call.Response.StatusCode})
+	}
+
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		attrs = append(attrs, Attribute{Key: AttributeErrorCode, Value: apiErr.Code})
+	}
+	span.SetAttributes(attrs...)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// productIDOf returns the product ID targeted by call, read from the query string
+// or from the ProductID field of the params struct. Returns an empty string if there is none.
+func productIDOf(call *Call) string {
+	if productID := call.Query["productId"]; productID != "" {
+		return productID
+	}
+
+	v := reflect.ValueOf(call.Params)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	field := v.FieldByName("ProductID")
+	if field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
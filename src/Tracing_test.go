@@ -0,0 +1,122 @@
+package keymint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recordingTracer is a Tracer recording the spans it starts.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+// recordingSpan is a span started by a recordingTracer.
+type recordingSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+type spanKey struct{}
+
+func (tr *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	span := &recordingSpan{name: name, attrs: make(map[string]interface{})}
+	span.SetAttributes(attrs...)
+	tr.spans = append(tr.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (tr *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanKey{}).(*recordingSpan); ok {
+		header.Set("Traceparent", "span:"+span.name)
+	}
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) { s.err = err }
+func (s *recordingSpan) End()                  { s.ended = true }
+
+func TestTracerSpans(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		if r.URL.Path == "/key/block" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"License key is blocked","code":3}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	client, _ := newTestClient(t, server.URL, WithTracer(tracer))
+
+	if _, err := client.GetKey(GetKeyParams{ProductID: "prod_1", LicenseKey: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	if traceparent != "span:keymint.GetKey" {
+		t.Errorf("the API received traceparent %q, want the span of the call", traceparent)
+	}
+	_, blockErr := client.BlockKey(BlockKeyParams{ProductID: "prod_2", LicenseKey: "k"})
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(tracer.spans))
+	}
+	tests := []struct {
+		span  *recordingSpan
+		name  string
+		attrs map[string]interface{}
+		err   error
+	}{
+		{tracer.spans[0], "keymint.GetKey", map[string]interface{}{
+			AttributeOperation:  "GetKey",
+			AttributeEndpoint:   "/key",
+			AttributeHTTPMethod: "GET",
+			AttributeProductID:  "prod_1",
+			AttributeAttempts:   1,
+			AttributeHTTPStatus: 200,
+		}, nil},
+		{tracer.spans[1], "keymint.BlockKey", map[string]interface{}{
+			AttributeOperation:  "BlockKey",
+			AttributeEndpoint:   "/key/block",
+			AttributeHTTPMethod: "POST",
+			AttributeProductID:  "prod_2",
+			AttributeAttempts:   1,
+			AttributeHTTPStatus: 403,
+			AttributeErrorCode:  3,
+		}, blockErr},
+	}
+	for _, tt := range tests {
+		if tt.span.name != tt.name || !tt.span.ended {
+			t.Errorf("got span %q (ended: %t), want an ended span %q", tt.span.name, tt.span.ended, tt.name)
+		}
+		for key, want := range tt.attrs {
+			if got := tt.span.attrs[key]; got != want {
+				t.Errorf("%s: attribute %s = %v, want %v", tt.name, key, got, want)
+			}
+		}
+		if len(tt.span.attrs) != len(tt.attrs) {
+			t.Errorf("%s: got attributes %v, want %v", tt.name, tt.span.attrs, tt.attrs)
+		}
+		if tt.span.err != tt.err {
+			t.Errorf("%s: recorded error %v, want %v", tt.name, tt.span.err, tt.err)
+		}
+	}
+	if !errors.Is(tracer.spans[1].err, ErrKeyBlocked) {
+		t.Errorf("recorded %v, want the ApiError of the call", tracer.spans[1].err)
+	}
+}
@@ -1,6 +1,9 @@
 package keymint
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // NewCustomer represents the structure for creating a new customer when creating a license key.
 type NewCustomer struct {
@@ -14,10 +17,11 @@ type NewCustomer struct {
 type CreateKeyParams struct {
 	// ProductID is the unique identifier of the product.
 	ProductID string `json:"productId"`
-	// MaxActivations is the optional maximum number of times the key can be activated.
-	MaxActivations *string `json:"maxActivations,omitempty"`
-	// ExpiryDate is the optional expiration date of the key in ISO 8601 format.
-	ExpiryDate *string `json:"expiryDate,omitempty"`
+	// MaxActivations is the optional maximum number of times the key can be activated, or
+	// UnlimitedActivations. It is sent to the API as a string, and omitted if unlimited.
+	MaxActivations *int `json:"maxActivations,omitempty"`
+	// ExpiryDate is the optional expiration date of the key. The key never expires if it is zero.
+	ExpiryDate Time `json:"expiryDate,omitzero"`
 	// CustomerID is the optional ID of an existing customer to associate with the key.
 	CustomerID *string `json:"customerId,omitempty"`
 	// VersionID is the optional ID of a specific product version to associate with the key.
@@ -46,10 +50,20 @@ type ApiError struct {
 	Code int `json:"code"`
 	// Status is the optional HTTP status code.
 	Status *int `json:"status,omitempty"`
+	// RequestID is the request ID assigned by the API, if the response carried one.
+	RequestID string `json:"requestId,omitempty"`
+
+	// kind is the sentinel error describing the failure when it is known up front (e.g. ErrNetwork).
+	kind error
+	// err is the underlying error that caused the failure, if any.
+	err error
 }
 
 // Error implements the error interface for ApiError.
 func (e *ApiError) Error() string {
+	if e.Status != nil && e.RequestID != "" {
+		return fmt.Sprintf("KeyMint API Error (code: %d, status: %d, request ID: %s): %s", e.Code, *e.Status, e.RequestID, e.Message)
+	}
 	if e.Status != nil {
 		return fmt.Sprintf("KeyMint API Error (code: %d, status: %d): %s", e.Code, *e.Status, e.Message)
 	}
@@ -83,7 +97,7 @@ type ActivateKeyResponse struct {
 	// VersionID is the optional associated product version ID.
 	VersionID *string `json:"versionId,omitempty"`
 	// Version is the optional detailed product version information.
-	Version map[string]interface{} `json:"version,omitempty"`
+	Version *Version `json:"version,omitempty"`
 	// AllowedHosts is an optional list of authorized machine IDs.
 	AllowedHosts []string `json:"allowedHosts,omitempty"`
 }
@@ -115,7 +129,7 @@ type DeviceDetails struct {
 	// IPAddress is the updated field name.
 	IPAddress *string `json:"ipAddress,omitempty"`
 	// ActivationTime is the updated field name.
-	ActivationTime string `json:"activationTime"`
+	ActivationTime Time `json:"activationTime"`
 }
 
 // LicenseDetails represents license details included in the GetKeyResponse.
@@ -134,14 +148,14 @@ type LicenseDetails struct {
 	Devices []DeviceDetails `json:"devices"`
 	// Activated indicates if the license is activated.
 	Activated bool `json:"activated"`
-	// ExpirationDate is the updated field name.
-	ExpirationDate *string `json:"expirationDate,omitempty"`
+	// ExpirationDate is the expiration date of the license, or the zero Time if it never expires.
+	ExpirationDate Time `json:"expirationDate,omitzero"`
 	// Metadata is the optional custom dictionary attached to the license key.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 	// VersionID is the optional associated product version ID.
 	VersionID *string `json:"versionId,omitempty"`
 	// Version is the optional detailed product version information.
-	Version map[string]interface{} `json:"version,omitempty"`
+	Version *Version `json:"version,omitempty"`
 	// AllowedHosts is the optional list of authorized machine IDs.
 	AllowedHosts []string `json:"allowedHosts,omitempty"`
 }
@@ -253,9 +267,9 @@ type Customer struct {
 	// Active indicates if the customer is active.
 	Active bool `json:"active"`
 	// CreatedAt is the timestamp when the customer was created.
-	CreatedAt string `json:"createdAt"`
+	CreatedAt Time `json:"createdAt"`
 	// UpdatedAt is the timestamp when the customer was last updated.
-	UpdatedAt string `json:"updatedAt"`
+	UpdatedAt Time `json:"updatedAt"`
 	// CreatedBy is the identifier of the user who created the customer.
 	CreatedBy string `json:"createdBy"`
 }
@@ -316,8 +330,8 @@ type CustomerLicenseKey struct {
 	Activations int `json:"activations"`
 	// Activated indicates if the license key is activated.
 	Activated bool `json:"activated"`
-	// ExpirationDate is the expiration date of the license key.
-	ExpirationDate *string `json:"expirationDate,omitempty"`
+	// ExpirationDate is the expiration date of the license key, or the zero Time if it never expires.
+	ExpirationDate Time `json:"expirationDate,omitzero"`
 	// Metadata is the optional custom dictionary attached to the license key.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 	// VersionID is the optional associated product version ID.
@@ -448,9 +462,9 @@ type FloatingCheckoutResponse struct {
 	// SessionSecret is the temporary session secret key.
 	SessionSecret string `json:"sessionSecret"`
 	// NextNonce is the rotating nonce string to use for the next request.
-	NextNonce string `json:"nextNonce"`
-	// ExpiresAt is the expiration time of the session in ISO 8601 format.
-	ExpiresAt string `json:"expiresAt"`
+	NextNonce Nonce `json:"nextNonce"`
+	// ExpiresAt is the expiration time of the session.
+	ExpiresAt Time `json:"expiresAt"`
 	// HeartbeatInterval is the interval (in seconds) the client must heartbeat within.
 	HeartbeatInterval int `json:"heartbeatInterval"`
 	// Metadata is the optional custom dictionary attached to the license key.
@@ -474,7 +488,7 @@ type FloatingHeartbeatParams struct {
 	// SessionID is the unique session ID.
 	SessionID string `json:"sessionId"`
 	// Timestamp is the rotating nonce (nextNonce) received from the previous response.
-	Timestamp interface{} `json:"timestamp"`
+	Timestamp Nonce `json:"timestamp"`
 	// Signature is the HMAC-SHA256 signature generated using the sessionSecret over the payload 'sessionId:nonce'.
 	Signature string `json:"signature"`
 }
@@ -485,10 +499,10 @@ type FloatingHeartbeatResponse struct {
 	Code int `json:"code"`
 	// Message is the response status message.
 	Message string `json:"message"`
-	// ExpiresAt is the extended expiration time of the session in ISO 8601 format.
-	ExpiresAt string `json:"expiresAt"`
+	// ExpiresAt is the extended expiration time of the session.
+	ExpiresAt Time `json:"expiresAt"`
 	// NextNonce is the newly rotated nonce to be used for the next subsequent heartbeat.
-	NextNonce string `json:"nextNonce"`
+	NextNonce Nonce `json:"nextNonce"`
 }
 
 // FloatingCheckinParams represents parameters for the floating license checkin API endpoint.
@@ -500,7 +514,7 @@ type FloatingCheckinParams struct {
 	// SessionID is the unique session ID.
 	SessionID string `json:"sessionId"`
 	// Timestamp is the rotating nonce (nextNonce) received from the previous response.
-	Timestamp interface{} `json:"timestamp"`
+	Timestamp Nonce `json:"timestamp"`
 	// Signature is the HMAC-SHA256 signature generated using the sessionSecret over the payload 'sessionId:nonce'.
 	Signature string `json:"signature"`
 }
@@ -515,5 +529,17 @@ type FloatingCheckinResponse struct {
 
 // RequestOptions contains optional parameters for Keymint API requests (e.g. idempotency keys).
 type RequestOptions struct {
+	// IdempotencyKey is sent as the Idempotency-Key header. Mutating calls get a generated key when empty.
 	IdempotencyKey string
+	// Headers are extra headers sent with this request only (e.g. correlation IDs).
+	Headers map[string]string
+	// Timeout bounds the whole call, including retries. Zero means no per-call timeout.
+	Timeout time.Duration
+	// APIKey overrides the client's API key for this request (e.g. a per-tenant key).
+	APIKey string
+	// BaseURL overrides the client's API base URL for this request (e.g. staging vs production).
+	BaseURL string
+	// Response, if set, receives the metadata of the HTTP response (status, headers,
+	// request ID, rate-limit state, server time) once the call completed, even if it failed.
+	Response *ResponseMetadata
 }
@@ -0,0 +1,310 @@
+package keymint
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// FieldError describes an invalid field of a params struct.
+type FieldError struct {
+	// Field is the JSON name of the field, with nested fields separated by dots (e.g. "newCustomer.email").
+	Field string `json:"field"`
+	// Message describes why the value is invalid (e.g. "is required").
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid fields of a params struct. It is returned by the
+// Validate methods, and wrapped in an ApiError of kind ErrValidation when a Client method
+// is called with invalid params, in which case no request is sent:
+//
+//	var validationErr *keymint.ValidationError
+//	if errors.As(err, &validationErr) {
+//		for _, field := range validationErr.Fields {
+//			form.SetError(field.Field, field.Message)
+//		}
+//	}
+type ValidationError struct {
+	// Fields lists the invalid fields, in declaration order.
+	Fields []FieldError `json:"fields"`
+}
+
+// Error implements the error interface for ValidationError.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return "invalid params: " + strings.Join(messages, "; ")
+}
+
+// ValidateParams runs the Validate method of params, if it has one, and returns the error
+// a Client method returns without sending a request when called with invalid params: an
+// ApiError of kind ErrValidation wrapping the ValidationError. Fakes of the API use it to
+// reject params the same way.
+func ValidateParams(params interface{}) error {
+	var f fieldErrors
+	f.params(params)
+	return f.apiError()
+}
+
+// validate is ValidateParams, also checking the metadata of CreateKey params against the
+// metadata schema.
+func (c *Client) validate(params interface{}) error {
+	var f fieldErrors
+	f.params(params)
+	if p, ok := params.(CreateKeyParams); ok && c.metadataSchema != nil && p.Metadata != nil {
+		f.merge("metadata", c.metadataSchema(p.Metadata))
+	}
+	return f.apiError()
+}
+
+// fieldErrors accumulates the invalid fields of a params struct.
+type fieldErrors []FieldError
+
+// add records an invalid field.
+func (f *fieldErrors) add(field, message string) {
+	*f = append(*f, FieldError{Field: field, Message: message})
+}
+
+// merge records the fields listed by err, a *ValidationError, under prefix. Any other
+// error is recorded as an invalid prefix field.
+func (f *fieldErrors) merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		f.add(prefix, err.Error())
+		return
+	}
+	for _, field := range validationErr.Fields {
+		switch {
+		case prefix == "":
+		case field.Field == "":
+			field.Field = prefix
+		default:
+			field.Field = prefix + "." + field.Field
+		}
+		f.add(field.Field, field.Message)
+	}
+}
+
+// params records the invalid fields reported by the Validate method of params, if it has one.
+func (f *fieldErrors) params(params interface{}) {
+	if validator, ok := params.(interface{ Validate() error }); ok {
+		f.merge("", validator.Validate())
+	}
+}
+
+// apiError returns the ApiError of kind ErrValidation wrapping the recorded fields, or nil
+// if there are none.
+func (f fieldErrors) apiError() error {
+	err := f.err()
+	if err == nil {
+		return nil
+	}
+	return &ApiError{
+		Message: err.Error(),
+		Code:    -1,
+		kind:    ErrValidation,
+		err:     err,
+	}
+}
+
+// required records field as invalid if value is empty.
+func (f *fieldErrors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		f.add(field, "is required")
+	}
+}
+
+// notEmpty records field as invalid if it is set to an empty value.
+func (f *fieldErrors) notEmpty(field string, value *string) {
+	if value != nil && strings.TrimSpace(*value) == "" {
+		f.add(field, "must not be empty")
+	}
+}
+
+// email records field as invalid if value is not a plain email address.
+func (f *fieldErrors) email(field, value string) {
+	if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+		f.add(field, "must be a valid email address")
+	}
+}
+
+// positive records field as invalid if it is set to a value below 1.
+func (f *fieldErrors) positive(field string, value *int) {
+	if value != nil && *value < 1 {
+		f.add(field, "must be a positive integer")
+	}
+}
+
+// license records the product and license key fields shared by the key endpoints as invalid if empty.
+func (f *fieldErrors) license(productID, licenseKey string) {
+	f.required("productId", productID)
+	f.required("licenseKey", licenseKey)
+}
+
+// session records the fields of a signed floating license request as invalid.
+func (f *fieldErrors) session(sessionID string, timestamp Nonce, signature string) {
+	f.required("sessionId", sessionID)
+	f.required("timestamp", timestamp.Value)
+	if _, err := hex.DecodeString(signature); err != nil || len(signature) != 64 {
+		f.add("signature", "must be a 64-character hexadecimal HMAC-SHA256 signature")
+	}
+}
+
+// err returns a ValidationError listing the invalid fields, or nil if there is none.
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: f}
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p CreateKeyParams) Validate() error {
+	var f fieldErrors
+	f.required("productId", p.ProductID)
+	if p.MaxActivations != nil && *p.MaxActivations < 0 {
+		f.add("maxActivations", "must be a non-negative integer")
+	}
+	f.notEmpty("customerId", p.CustomerID)
+	f.notEmpty("versionId", p.VersionID)
+	if p.NewCustomer != nil {
+		f.required("newCustomer.name", p.NewCustomer.Name)
+		if p.NewCustomer.Email != nil {
+			f.email("newCustomer.email", *p.NewCustomer.Email)
+		}
+	}
+	for i, host := range p.AllowedHosts {
+		if strings.TrimSpace(host) == "" {
+			f.add(fmt.Sprintf("allowedHosts.%d", i), "must not be empty")
+		}
+	}
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p ActivateKeyParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	f.notEmpty("hostId", p.HostID)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p DeactivateKeyParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	f.notEmpty("hostId", p.HostID)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p GetKeyParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p BlockKeyParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p UnblockKeyParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p FloatingCheckoutParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	f.required("hostId", p.HostID)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p FloatingHeartbeatParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	f.session(p.SessionID, p.Timestamp, p.Signature)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p FloatingCheckinParams) Validate() error {
+	var f fieldErrors
+	f.license(p.ProductID, p.LicenseKey)
+	f.session(p.SessionID, p.Timestamp, p.Signature)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p CreateCustomerParams) Validate() error {
+	var f fieldErrors
+	f.required("name", p.Name)
+	if strings.TrimSpace(p.Email) == "" {
+		f.add("email", "is required")
+	} else {
+		f.email("email", p.Email)
+	}
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p GetAllCustomersParams) Validate() error {
+	var f fieldErrors
+	f.positive("page", p.Page)
+	f.positive("limit", p.Limit)
+	f.notEmpty("email", p.Email)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p GetCustomerWithKeysParams) Validate() error {
+	var f fieldErrors
+	f.required("customerId", p.CustomerID)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p UpdateCustomerParams) Validate() error {
+	var f fieldErrors
+	f.required("customerId", p.CustomerID)
+	f.notEmpty("name", p.Name)
+	if p.Email != nil {
+		f.email("email", *p.Email)
+	}
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p DeleteCustomerParams) Validate() error {
+	var f fieldErrors
+	f.required("customerId", p.CustomerID)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p ToggleCustomerStatusParams) Validate() error {
+	var f fieldErrors
+	f.required("customerId", p.CustomerID)
+	return f.err()
+}
+
+// Validate checks the params locally, returning a *ValidationError listing every invalid field.
+func (p GetCustomerByIdParams) Validate() error {
+	var f fieldErrors
+	f.required("customerId", p.CustomerID)
+	return f.err()
+}
@@ -0,0 +1,118 @@
+package keymint
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateParams(t *testing.T) {
+	signature := strings.Repeat("ab", 32)
+	nonce := Nonce{Value: "1705312800123", Numeric: true}
+
+	tests := []struct {
+		name       string
+		params     interface{}
+		wantFields []string
+	}{
+		{"CreateKey", CreateKeyParams{ProductID: "p", MaxActivations: Ptr(0), NewCustomer: &NewCustomer{Name: "Ada", Email: Ptr("ada@example.com")}, AllowedHosts: []string{"host"}}, nil},
+		{"CreateKey missing", CreateKeyParams{MaxActivations: Ptr(-1), CustomerID: Ptr(" "), NewCustomer: &NewCustomer{Email: Ptr("Ada <ada@example.com>")}, AllowedHosts: []string{"host", ""}},
+			[]string{"productId", "maxActivations", "customerId", "newCustomer.name", "newCustomer.email", "allowedHosts.1"}},
+		{"ActivateKey", ActivateKeyParams{ProductID: "p", LicenseKey: "k", HostID: Ptr("host")}, nil},
+		{"ActivateKey missing", ActivateKeyParams{HostID: Ptr("")}, []string{"productId", "licenseKey", "hostId"}},
+		{"DeactivateKey", DeactivateKeyParams{ProductID: "p", LicenseKey: "k"}, nil},
+		{"DeactivateKey missing", DeactivateKeyParams{ProductID: "p"}, []string{"licenseKey"}},
+		{"GetKey", GetKeyParams{ProductID: "p", LicenseKey: "k"}, nil},
+		{"GetKey missing", GetKeyParams{LicenseKey: "k"}, []string{"productId"}},
+		{"BlockKey", BlockKeyParams{ProductID: "p", LicenseKey: "k"}, nil},
+		{"BlockKey missing", BlockKeyParams{ProductID: "p", LicenseKey: " "}, []string{"licenseKey"}},
+		{"UnblockKey", UnblockKeyParams{ProductID: "p", LicenseKey: "k"}, nil},
+		{"UnblockKey missing", UnblockKeyParams{}, []string{"productId", "licenseKey"}},
+		{"FloatingCheckout", FloatingCheckoutParams{ProductID: "p", LicenseKey: "k", HostID: "host"}, nil},
+		{"FloatingCheckout missing", FloatingCheckoutParams{ProductID: "p", LicenseKey: "k"}, []string{"hostId"}},
+		{"FloatingHeartbeat", FloatingHeartbeatParams{ProductID: "p", LicenseKey: "k", SessionID: "s", Timestamp: nonce, Signature: signature}, nil},
+		{"FloatingHeartbeat missing", FloatingHeartbeatParams{ProductID: "p", LicenseKey: "k", Signature: "not hex"}, []string{"sessionId", "timestamp", "signature"}},
+		{"FloatingCheckin", FloatingCheckinParams{ProductID: "p", LicenseKey: "k", SessionID: "s", Timestamp: nonce, Signature: signature}, nil},
+		{"FloatingCheckin missing", FloatingCheckinParams{ProductID: "p", LicenseKey: "k", SessionID: "s", Timestamp: nonce, Signature: signature[:62]}, []string{"signature"}},
+		{"CreateCustomer", CreateCustomerParams{Name: "Ada", Email: "ada@example.com"}, nil},
+		{"CreateCustomer missing", CreateCustomerParams{}, []string{"name", "email"}},
+		{"CreateCustomer invalid email", CreateCustomerParams{Name: "Ada", Email: "ada"}, []string{"email"}},
+		{"GetAllCustomers", GetAllCustomersParams{Page: Ptr(1), Limit: Ptr(50)}, nil},
+		{"GetAllCustomers invalid", GetAllCustomersParams{Page: Ptr(0), Limit: Ptr(-1), Email: Ptr("")}, []string{"page", "limit", "email"}},
+		{"GetCustomerWithKeys", GetCustomerWithKeysParams{CustomerID: "c"}, nil},
+		{"GetCustomerWithKeys missing", GetCustomerWithKeysParams{}, []string{"customerId"}},
+		{"UpdateCustomer", UpdateCustomerParams{CustomerID: "c", Name: Ptr("Ada"), Email: Ptr("ada@example.com")}, nil},
+		{"UpdateCustomer missing", UpdateCustomerParams{Name: Ptr(""), Email: Ptr("ada@")}, []string{"customerId", "name", "email"}},
+		{"DeleteCustomer", DeleteCustomerParams{CustomerID: "c"}, nil},
+		{"DeleteCustomer missing", DeleteCustomerParams{}, []string{"customerId"}},
+		{"ToggleCustomerStatus", ToggleCustomerStatusParams{CustomerID: "c"}, nil},
+		{"ToggleCustomerStatus missing", ToggleCustomerStatusParams{CustomerID: "\t"}, []string{"customerId"}},
+		{"GetCustomerById", GetCustomerByIdParams{CustomerID: "c"}, nil},
+		{"GetCustomerById missing", GetCustomerByIdParams{}, []string{"customerId"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParams(tt.params)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("got error %v for valid params", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("got error %v, want ErrValidation", err)
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got error %v, want it to wrap a *ValidationError", err)
+			}
+			var fields []string
+			for _, field := range validationErr.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("got invalid fields %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := CreateCustomerParams{Email: "ada"}.Validate()
+	if want := "invalid params: name is required; email must be a valid email address"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
+
+func TestClientValidatesBeforeSending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the client sent invalid params")
+	}))
+	defer server.Close()
+
+	schema := func(metadata map[string]interface{}) error {
+		if _, ok := metadata["plan"]; !ok {
+			return &ValidationError{Fields: []FieldError{{Field: "plan", Message: "is required"}}}
+		}
+		return nil
+	}
+	client, attempts := newTestClient(t, server.URL, WithMetadataSchema(schema))
+	_, err := client.CreateKey(CreateKeyParams{Metadata: map[string]interface{}{}})
+
+	var validationErr *ValidationError
+	if !errors.Is(err, ErrValidation) || !errors.As(err, &validationErr) {
+		t.Fatalf("got error %v, want ErrValidation", err)
+	}
+	want := []FieldError{{Field: "productId", Message: "is required"}, {Field: "metadata.plan", Message: "is required"}}
+	if !reflect.DeepEqual(validationErr.Fields, want) {
+		t.Errorf("got fields %+v, want %+v", validationErr.Fields, want)
+	}
+	if *attempts != 0 {
+		t.Errorf("got %d attempts, want none", *attempts)
+	}
+}
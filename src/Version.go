@@ -0,0 +1,280 @@
+package keymint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a product version a license key is associated with.
+type Version struct {
+	// ID is the product version ID.
+	ID string `json:"id"`
+	// ProductID is the product the version belongs to.
+	ProductID string `json:"productId,omitempty"`
+	// Name is the display name of the version (e.g. "Pro 2024").
+	Name string `json:"name,omitempty"`
+	// Semver is the semantic version of the release (e.g. "2.1.0"). It is decoded from the
+	// version field when the API does not send a semver field.
+	Semver string `json:"semver,omitempty"`
+	// ReleaseDate is when the version was released, or the zero Time if not reported.
+	ReleaseDate Time `json:"releaseDate,omitzero"`
+	// CreatedAt is when the version was created, or the zero Time if not reported.
+	CreatedAt Time `json:"createdAt,omitzero"`
+	// UpdatedAt is when the version was last updated, or the zero Time if not reported.
+	UpdatedAt Time `json:"updatedAt,omitzero"`
+	// Extra holds the fields returned by the API that are not modeled above.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unknown fields in Extra. Decoding is
+// lenient: the string fields also accept numbers (e.g. a numeric id), and a field that does
+// not decode into its modeled type, such as an unparseable date, is kept in Extra instead.
+func (v *Version) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if fields == nil {
+		return nil
+	}
+
+	var version Version
+	stringFields := map[string]*string{"id": &version.ID, "productId": &version.ProductID, "name": &version.Name, "semver": &version.Semver}
+	timeFields := map[string]*Time{"releaseDate": &version.ReleaseDate, "createdAt": &version.CreatedAt, "updatedAt": &version.UpdatedAt}
+
+	// The API may send the semantic version as version instead of semver.
+	semver, _ := lenientString(fields["semver"])
+	semverFromVersion, fallback := semver == "", ""
+
+	extra := make(map[string]interface{})
+	for name, raw := range fields {
+		if field, ok := stringFields[name]; ok {
+			if value, ok := lenientString(raw); ok {
+				*field = value
+				continue
+			}
+		}
+		if field, ok := timeFields[name]; ok && field.UnmarshalJSON(raw) == nil {
+			continue
+		}
+		if name == "version" && semverFromVersion {
+			if value, ok := lenientString(raw); ok && value != "" {
+				fallback = value
+				continue
+			}
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		extra[name] = value
+	}
+
+	if semverFromVersion {
+		version.Semver = fallback
+	}
+	if len(extra) > 0 {
+		version.Extra = extra
+	}
+	*v = version
+	return nil
+}
+
+// lenientString decodes a JSON string, number or null as a string.
+func lenientString(raw json.RawMessage) (string, bool) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value, true
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String(), true
+	}
+	return "", false
+}
+
+// MarshalJSON implements json.Marshaler, encoding the fields of Extra alongside the modeled ones.
+func (v Version) MarshalJSON() ([]byte, error) {
+	type version Version
+	data, err := json.Marshal(version(v))
+	if err != nil || len(v.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range v.Extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// SemanticVersion returns the parsed Semver of v.
+func (v Version) SemanticVersion() (SemanticVersion, error) {
+	return ParseSemanticVersion(v.Semver)
+}
+
+// Compare compares the version with appVersion, the semantic version of the running
+// application. It returns -1 if the version precedes appVersion, 0 if they are equal
+// and +1 if the version is newer.
+func (v Version) Compare(appVersion string) (int, error) {
+	version, err := v.SemanticVersion()
+	if err != nil {
+		return 0, err
+	}
+	app, err := ParseSemanticVersion(appVersion)
+	if err != nil {
+		return 0, err
+	}
+	return version.Compare(app), nil
+}
+
+// Covers reports whether a license for the version may run appVersion, the semantic version
+// of the running application: a license covers the releases of its major version and older
+// ones, so upgrading to a new major version requires a new license.
+//
+//	if ok, err := res.Version.Covers(appVersion); err == nil && !ok {
+//		// ask the user to upgrade their license
+//	}
+func (v Version) Covers(appVersion string) (bool, error) {
+	version, err := v.SemanticVersion()
+	if err != nil {
+		return false, err
+	}
+	app, err := ParseSemanticVersion(appVersion)
+	if err != nil {
+		return false, err
+	}
+	return app.Major <= version.Major, nil
+}
+
+// SemanticVersion is a parsed semantic version (https://semver.org).
+type SemanticVersion struct {
+	// Major is the major version.
+	Major int
+	// Minor is the minor version.
+	Minor int
+	// Patch is the patch version.
+	Patch int
+	// Prerelease is the pre-release version (e.g. "beta.1"), or empty for a release.
+	Prerelease string
+	// Build is the build metadata (e.g. "20240101"), ignored by comparisons.
+	Build string
+}
+
+// ParseSemanticVersion parses a semantic version such as "2.1.0", "v2.1.0-beta.1" or
+// "2.1.0+build.5". The "v" prefix is optional, and so are the minor and patch versions.
+func ParseSemanticVersion(value string) (SemanticVersion, error) {
+	invalid := fmt.Errorf("keymint: invalid semantic version %q", value)
+
+	var version SemanticVersion
+	rest := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	var hasBuild, hasPrerelease bool
+	rest, version.Build, hasBuild = strings.Cut(rest, "+")
+	rest, version.Prerelease, hasPrerelease = strings.Cut(rest, "-")
+	if (hasBuild && version.Build == "") || (hasPrerelease && version.Prerelease == "") {
+		return SemanticVersion{}, invalid
+	}
+
+	numbers := strings.Split(rest, ".")
+	if len(numbers) > 3 {
+		return SemanticVersion{}, invalid
+	}
+	for i, number := range numbers {
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 0 || number[0] == '+' {
+			return SemanticVersion{}, invalid
+		}
+		switch i {
+		case 0:
+			version.Major = n
+		case 1:
+			version.Minor = n
+		case 2:
+			version.Patch = n
+		}
+	}
+
+	if version.Prerelease != "" {
+		for _, identifier := range strings.Split(version.Prerelease, ".") {
+			if identifier == "" {
+				return SemanticVersion{}, invalid
+			}
+		}
+	}
+	return version, nil
+}
+
+// String formats v as a semantic version, without a "v" prefix.
+func (v SemanticVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1 if v precedes other, 0 if they have the same precedence and +1 if v
+// is newer, following the semantic versioning precedence rules: a pre-release precedes
+// its release, and build metadata is ignored.
+func (v SemanticVersion) Compare(other SemanticVersion) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := comparePrerelease(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(a) - len(b))
+}
+
+// comparePrerelease compares two pre-release identifiers: numeric identifiers compare
+// numerically and precede alphanumeric ones, which compare lexically.
+func comparePrerelease(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return sign(na - nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// sign returns -1, 0 or +1 depending on the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
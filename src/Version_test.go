@@ -0,0 +1,155 @@
+package keymint
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVersionDecodesLeniently(t *testing.T) {
+	var res struct {
+		Version *Version `json:"version"`
+	}
+	data := `{"version":{"id":12,"productId":"prod_1","version":"2.1.0","releaseDate":"2024-01-15","createdAt":"last tuesday","channel":"stable"}}`
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Version{
+		ID:          "12",
+		ProductID:   "prod_1",
+		Semver:      "2.1.0",
+		ReleaseDate: NewTime(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
+		Extra:       map[string]interface{}{"createdAt": "last tuesday", "channel": "stable"},
+	}
+	if !reflect.DeepEqual(res.Version, want) {
+		t.Errorf("got %+v, want %+v", res.Version, want)
+	}
+
+	encoded, err := json.Marshal(res.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Version
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, want) {
+		t.Errorf("round trip through %s: got %+v, want %+v", encoded, decoded, want)
+	}
+}
+
+func TestVersionSemverField(t *testing.T) {
+	tests := []struct {
+		data   string
+		semver string
+		extra  map[string]interface{}
+	}{
+		{`{"id":"v1","semver":"2.0.0"}`, "2.0.0", nil},
+		{`{"id":"v1","version":"2.0.0"}`, "2.0.0", nil},
+		{`{"id":"v1","version":2}`, "2", nil},
+		{`{"id":"v1","semver":"3.0.0","version":"2.0.0"}`, "3.0.0", map[string]interface{}{"version": "2.0.0"}},
+		{`{"id":"v1","semver":null,"version":"2.0.0"}`, "2.0.0", nil},
+	}
+	for _, test := range tests {
+		var v Version
+		if err := json.Unmarshal([]byte(test.data), &v); err != nil {
+			t.Fatalf("decoding %s: %v", test.data, err)
+		}
+		if v.Semver != test.semver || !reflect.DeepEqual(v.Extra, test.extra) {
+			t.Errorf("decoding %s: got semver %q and extra %v, want %q and %v", test.data, v.Semver, v.Extra, test.semver, test.extra)
+		}
+	}
+}
+
+func TestParseSemanticVersion(t *testing.T) {
+	accepted := []struct {
+		value string
+		want  SemanticVersion
+	}{
+		{"2.1.0", SemanticVersion{Major: 2, Minor: 1}},
+		{"v2.1.3", SemanticVersion{Major: 2, Minor: 1, Patch: 3}},
+		{"2", SemanticVersion{Major: 2}},
+		{"2.1", SemanticVersion{Major: 2, Minor: 1}},
+		{"1.0.0-beta.1", SemanticVersion{Major: 1, Prerelease: "beta.1"}},
+		{"1.0.0+build.5", SemanticVersion{Major: 1, Build: "build.5"}},
+		{"1.0.0-rc.1+20240101", SemanticVersion{Major: 1, Prerelease: "rc.1", Build: "20240101"}},
+		{" 1.2.3 ", SemanticVersion{Major: 1, Minor: 2, Patch: 3}},
+	}
+	for _, test := range accepted {
+		got, err := ParseSemanticVersion(test.value)
+		if err != nil {
+			t.Errorf("ParseSemanticVersion(%q): %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseSemanticVersion(%q) = %+v, want %+v", test.value, got, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "v", "1.2.3.4", "1..2", "1.-2.0", "1.+2.0", "a.b.c", "1.0.0-", "1.0.0+", "1.0.0-beta..1"} {
+		if got, err := ParseSemanticVersion(value); err == nil {
+			t.Errorf("ParseSemanticVersion(%q) = %+v, want an error", value, got)
+		}
+	}
+}
+
+func TestSemanticVersionCompare(t *testing.T) {
+	// Each version precedes the next one, following the example of the semver specification.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := ParseSemanticVersion(ordered[i])
+			b, _ := ParseSemanticVersion(ordered[j])
+			if got, want := a.Compare(b), sign(i-j); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := ParseSemanticVersion("1.0.0+build.1")
+	b, _ := ParseSemanticVersion("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Errorf("build metadata changed the precedence of %s and %s", a, b)
+	}
+}
+
+func TestVersionCovers(t *testing.T) {
+	v := Version{Semver: "2.1.0"}
+	tests := []struct {
+		appVersion string
+		compare    int
+		covers     bool
+	}{
+		{"1.9.0", 1, true},
+		{"2.1.0", 0, true},
+		{"2.5.0", -1, true},
+		{"3.0.0", -1, false},
+	}
+	for _, test := range tests {
+		if got, err := v.Compare(test.appVersion); err != nil || got != test.compare {
+			t.Errorf("Compare(%q) = %d, %v, want %d", test.appVersion, got, err, test.compare)
+		}
+		if got, err := v.Covers(test.appVersion); err != nil || got != test.covers {
+			t.Errorf("Covers(%q) = %t, %v, want %t", test.appVersion, got, err, test.covers)
+		}
+	}
+
+	if _, err := (Version{}).Covers("1.0.0"); err == nil {
+		t.Error("Covers succeeded without a semantic version, want an error")
+	}
+}
@@ -0,0 +1,308 @@
+// Package cassette records the HTTP interactions of a keymint.Client to a file once,
+// then replays them, so that integration tests run deterministically without network.
+//
+// A Cassette is an http.RoundTripper. Plug it into the client with keymint.WithHTTPClient:
+//
+//	mode := cassette.Replay
+//	if os.Getenv("KEYMINT_RECORD") != "" {
+//		mode = cassette.Record
+//	}
+//	c, err := cassette.New("testdata/activate.json", mode, nil)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer c.Save()
+//
+//	client, _ := keymint.New(apiKey, "", keymint.WithHTTPClient(&http.Client{Transport: c}))
+//
+// Secrets never reach the cassette file: license keys, floating session secrets, heartbeat
+// signatures and the values of credential headers (Authorization, Proxy-Authorization,
+// Cookie and Set-Cookie, plus those added with WithScrubbedHeaders) are replaced with
+// "[REDACTED]" before recording, in requests and responses alike.
+// Replayed requests are scrubbed the same way before being matched, by method, path,
+// query and JSON body, against the recorded ones.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Cassette records or replays interactions.
+type Mode int
+
+const (
+	// Replay answers requests with the interactions recorded in the cassette file,
+	// without sending them.
+	Replay Mode = iota
+	// Record sends requests and records the interactions, written to the cassette file by Save.
+	Record
+)
+
+// ErrNoMatch indicates a replayed request has no recorded interaction left to answer it.
+var ErrNoMatch = errors.New("cassette: no recorded interaction matches the request")
+
+// Cassette records or replays HTTP interactions. It is safe for concurrent use.
+type Cassette struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	// scrubbedHeaders holds the canonical names of the headers scrubbed from interactions.
+	scrubbedHeaders map[string]bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	// Request is the scrubbed request.
+	Request Request `json:"request"`
+	// Response is the scrubbed response.
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	// Method is the HTTP method.
+	Method string `json:"method"`
+	// Path is the URL path.
+	Path string `json:"path"`
+	// Query is the encoded query string, without the leading "?".
+	Query string `json:"query,omitempty"`
+	// Header holds the request headers.
+	Header http.Header `json:"header,omitempty"`
+	// Body is the request body.
+	Body string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int `json:"statusCode"`
+	// Header holds the response headers.
+	Header http.Header `json:"header,omitempty"`
+	// Body is the response body.
+	Body string `json:"body,omitempty"`
+}
+
+// Option configures a Cassette.
+type Option func(*Cassette)
+
+// WithScrubbedHeaders scrubs the values of the named headers, in addition to the default
+// credential headers, from the requests and responses of recorded interactions. Names are
+// case-insensitive.
+func WithScrubbedHeaders(names ...string) Option {
+	return func(c *Cassette) {
+		for _, name := range names {
+			c.scrubbedHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// file is the JSON document stored in a cassette file.
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// New creates a cassette backed by the file at path.
+//
+// In Replay mode the file is loaded, and New fails if it cannot be read. In Record mode
+// requests are sent through transport (http.DefaultTransport if nil), and the recorded
+// interactions replace the file's content when Save is called.
+func New(path string, mode Mode, transport http.RoundTripper, opts ...Option) (*Cassette, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c := &Cassette{path: path, mode: mode, transport: transport, scrubbedHeaders: map[string]bool{}}
+	WithScrubbedHeaders(defaultScrubbedHeaders...)(c)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+
+	if mode == Replay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to load %s: %w", path, err)
+		}
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("cassette: failed to parse %s: %w", path, err)
+		}
+		c.interactions = f.Interactions
+		c.replayed = make([]bool, len(f.Interactions))
+	}
+	return c, nil
+}
+
+// Mode returns the mode of the cassette.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Interactions returns a copy of the recorded interactions.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file, creating its directory
+// if needed. It does nothing in Replay mode.
+func (c *Cassette) Save() error {
+	if c.mode != Record {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(file{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("cassette: failed to encode interactions: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("cassette: failed to save %s: %w", c.path, err)
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cassette: failed to save %s: %w", c.path, err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper, recording or replaying req depending on the mode.
+// In Replay mode, a request without a matching interaction fails with an error wrapping ErrNoMatch.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read request body: %w", err)
+	}
+	recorded := c.scrubRequest(req, body)
+
+	if c.mode == Replay {
+		return c.replay(req, recorded)
+	}
+	return c.record(req, recorded)
+}
+
+// record sends req and records the interaction.
+func (c *Cassette) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// Scrubbing changes the body length.
+	header := c.scrubHeader(resp.Header)
+	header.Del("Content-Length")
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(scrubJSON(respBody)),
+		},
+	})
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay answers req with the first recorded interaction matching it that was not replayed yet.
+func (c *Cassette) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matches := 0
+	for i, interaction := range c.interactions {
+		if !matchRequest(interaction.Request, recorded) {
+			continue
+		}
+		matches++
+		if c.replayed[i] {
+			continue
+		}
+		c.replayed[i] = true
+
+		recordedResp := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recordedResp.StatusCode, http.StatusText(recordedResp.StatusCode)),
+			StatusCode:    recordedResp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recordedResp.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recordedResp.Body)),
+			ContentLength: int64(len(recordedResp.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	description := recorded.Method + " " + recorded.Path
+	if recorded.Query != "" {
+		description += "?" + recorded.Query
+	}
+	if recorded.Body != "" {
+		description += " with body " + recorded.Body
+	}
+	if matches > 0 {
+		return nil, &noMatchError{fmt.Sprintf("%s: %s (its %d recorded interaction(s) were already replayed) in %s", ErrNoMatch, description, matches, c.path)}
+	}
+	return nil, &noMatchError{fmt.Sprintf("%s: %s in %s", ErrNoMatch, description, c.path)}
+}
+
+// noMatchError is the error of a replayed request without a matching interaction. It wraps
+// ErrNoMatch, and is permanent so that the client does not retry the request.
+type noMatchError struct {
+	message string
+}
+
+// Error implements error.
+func (e *noMatchError) Error() string {
+	return e.message
+}
+
+// Unwrap returns ErrNoMatch.
+func (e *noMatchError) Unwrap() error {
+	return ErrNoMatch
+}
+
+// Permanent reports that sending the request again cannot succeed.
+func (e *noMatchError) Permanent() bool {
+	return true
+}
+
+// readBody reads the body of req and restores it so that req can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
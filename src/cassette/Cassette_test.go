@@ -0,0 +1,138 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+	"github.com/keymint-dev/keymint-go/src/cassette"
+)
+
+const licenseKey = "ABCD-EFGH-IJKL-MNOP"
+
+// newClient returns a client sending its requests through c, with fast retries, and a
+// pointer to the number of attempts made by the last call.
+func newClient(t *testing.T, baseURL string, c *cassette.Cassette) (*keymint.Client, *int) {
+	t.Helper()
+	attempts := new(int)
+	client, err := keymint.New("live_api_key", baseURL,
+		keymint.WithHTTPClient(&http.Client{Transport: c}),
+		keymint.WithRetryPolicy(keymint.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		keymint.WithInterceptors(func(ctx context.Context, call *keymint.Call, next keymint.Invoker) error {
+			err := next(ctx, call)
+			*attempts = call.Attempts
+			return err
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, attempts
+}
+
+// record records one GetKey call to a server setting credential headers, and returns the
+// path of the saved cassette.
+func record(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=cookie_value")
+		w.Header().Set("X-Session-Token", "session_token")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"data":{"license":{"key":"` + licenseKey + `","productId":"p"}}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c, err := cassette.New(path, cassette.Record, nil, cassette.WithScrubbedHeaders("x-session-token", "X-Client-Secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := newClient(t, server.URL, c)
+	res, err := client.GetKey(keymint.GetKeyParams{ProductID: "p", LicenseKey: licenseKey},
+		&keymint.RequestOptions{Headers: map[string]string{"X-Client-Secret": "client_secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.License.Key != licenseKey {
+		t.Errorf("got key %q while recording, want the unscrubbed %q", res.Data.License.Key, licenseKey)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	data, err := os.ReadFile(record(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+
+	for _, secret := range []string{"live_api_key", "client_secret", "cookie_value", "session_token", licenseKey} {
+		if strings.Contains(saved, secret) {
+			t.Errorf("the cassette contains %q:\n%s", secret, saved)
+		}
+	}
+	if !strings.Contains(saved, "Bearer [REDACTED]") {
+		t.Errorf("the cassette does not keep the Authorization scheme:\n%s", saved)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	path := record(t)
+
+	tests := []struct {
+		name     string
+		params   keymint.GetKeyParams
+		wantMiss string
+	}{
+		{name: "recorded request", params: keymint.GetKeyParams{ProductID: "p", LicenseKey: licenseKey}},
+		{name: "already replayed", params: keymint.GetKeyParams{ProductID: "p", LicenseKey: licenseKey}, wantMiss: "already replayed"},
+		{name: "other product", params: keymint.GetKeyParams{ProductID: "q", LicenseKey: licenseKey}, wantMiss: "productId=q"},
+	}
+
+	c, err := cassette.New(path, cassette.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing listens on the base URL: replayed requests are never sent.
+	client, attempts := newClient(t, "http://127.0.0.1:1", c)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := client.GetKey(tt.params)
+			if tt.wantMiss == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Data.License.Key != "[REDACTED]" {
+					t.Errorf("got key %q, want the scrubbed recording", res.Data.License.Key)
+				}
+				return
+			}
+
+			if !errors.Is(err, cassette.ErrNoMatch) {
+				t.Fatalf("got error %v, want ErrNoMatch", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMiss) {
+				t.Errorf("got error %q, want it to mention %q", err, tt.wantMiss)
+			}
+			if *attempts != 1 {
+				t.Errorf("got %d attempts, want 1: a replay miss is not retried", *attempts)
+			}
+		})
+	}
+}
+
+func TestReplayMissingFile(t *testing.T) {
+	if _, err := cassette.New(filepath.Join(t.TempDir(), "missing.json"), cassette.Replay, nil); err == nil {
+		t.Error("got no error for a missing cassette file")
+	}
+}
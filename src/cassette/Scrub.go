@@ -0,0 +1,111 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// redacted replaces the secrets scrubbed from cassettes.
+const redacted = "[REDACTED]"
+
+// sensitiveFields lists the JSON fields and query parameters scrubbed from cassettes.
+var sensitiveFields = map[string]bool{
+	"licenseKey":    true,
+	"key":           true,
+	"sessionSecret": true,
+	"signature":     true,
+}
+
+// defaultScrubbedHeaders lists the headers scrubbed from every cassette: they carry
+// credentials or session state.
+var defaultScrubbedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// scrubRequest returns the scrubbed recording of req, whose body is body.
+func (c *Cassette) scrubRequest(req *http.Request, body []byte) Request {
+	query := req.URL.Query()
+	for name := range query {
+		if sensitiveFields[name] {
+			query[name] = []string{redacted}
+		}
+	}
+
+	return Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  query.Encode(),
+		Header: c.scrubHeader(req.Header),
+		Body:   string(scrubJSON(body)),
+	}
+}
+
+// scrubHeader returns a copy of header with the values of the scrubbed headers replaced.
+// The scheme of an authorization value, such as "Bearer", is kept.
+func (c *Cassette) scrubHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for name := range c.scrubbedHeaders {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		scrubbed := make([]string, len(values))
+		for i, value := range values {
+			scrubbed[i] = redacted
+			if scheme, _, ok := strings.Cut(value, " "); ok && strings.HasSuffix(name, "Authorization") {
+				scrubbed[i] = scheme + " " + redacted
+			}
+		}
+		header[name] = scrubbed
+	}
+	return header
+}
+
+// matchRequest reports whether the scrubbed requests a and b have the same method, path,
+// query and body. Headers are ignored: they carry per-call values such as idempotency keys.
+func matchRequest(a, b Request) bool {
+	return a.Method == b.Method && a.Path == b.Path && a.Query == b.Query && a.Body == b.Body
+}
+
+// scrubJSON scrubs sensitive fields anywhere in a JSON document, and re-encodes it with
+// sorted keys so that equivalent documents compare equal. Bodies that are not valid JSON
+// are returned unchanged.
+func scrubJSON(data []byte) []byte {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(scrubValue(value)); err != nil {
+		return data
+	}
+	return []byte(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// scrubValue walks a decoded JSON value and scrubs sensitive string fields.
+func scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := field.(string); ok && sensitiveFields[key] {
+				v[key] = redacted
+			} else {
+				v[key] = scrubValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = scrubValue(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,283 @@
+package keymintest
+
+import (
+	"context"
+	"iter"
+
+	"github.com/google/uuid"
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// defaultPageSize is the page size of GetAllCustomers when params.Limit is not set.
+const defaultPageSize = 10
+
+// CreateCustomer implements keymint.KeymintAPI.
+func (f *Fake) CreateCustomer(params keymint.CreateCustomerParams, opts ...*keymint.RequestOptions) (*keymint.CreateCustomerResponse, error) {
+	return f.CreateCustomerContext(context.Background(), params, opts...)
+}
+
+// CreateCustomerContext implements keymint.KeymintAPI.
+func (f *Fake) CreateCustomerContext(ctx context.Context, params keymint.CreateCustomerParams, opts ...*keymint.RequestOptions) (*keymint.CreateCustomerResponse, error) {
+	return call(f, ctx, "CreateCustomer", params, opts, func() (*keymint.CreateCustomerResponse, error) {
+		c, err := f.addCustomer(params.Name, params.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &keymint.CreateCustomerResponse{
+			ID:      c.ID,
+			Action:  "createCustomer",
+			Status:  true,
+			Message: "Customer created",
+			Code:    0,
+		}
+		res.Data.ID = c.ID
+		res.Data.Name = c.Name
+		res.Data.Email = c.Email
+		return res, nil
+	})
+}
+
+// GetAllCustomers implements keymint.KeymintAPI.
+func (f *Fake) GetAllCustomers(params keymint.GetAllCustomersParams, opts ...*keymint.RequestOptions) (*keymint.GetAllCustomersResponse, error) {
+	return f.GetAllCustomersContext(context.Background(), params, opts...)
+}
+
+// GetAllCustomersContext implements keymint.KeymintAPI.
+// Customers are listed in creation order, 10 per page unless params.Limit is set.
+func (f *Fake) GetAllCustomersContext(ctx context.Context, params keymint.GetAllCustomersParams, _ ...*keymint.RequestOptions) (*keymint.GetAllCustomersResponse, error) {
+	return call(f, ctx, "GetAllCustomers", params, nil, func() (*keymint.GetAllCustomersResponse, error) {
+		page, limit := 1, defaultPageSize
+		if params.Page != nil {
+			page = *params.Page
+		}
+		if params.Limit != nil {
+			limit = *params.Limit
+		}
+		if page < 1 || limit < 1 {
+			return nil, apiError(400, codeInvalidRequest, "page and limit must be positive integers")
+		}
+
+		var matches []keymint.Customer
+		for _, c := range f.customers {
+			if params.Email == nil || c.Email == *params.Email {
+				matches = append(matches, *c)
+			}
+		}
+
+		data := []keymint.Customer{}
+		if start := (page - 1) * limit; start < len(matches) {
+			data = append(data, matches[start:min(start+limit, len(matches))]...)
+		}
+
+		return &keymint.GetAllCustomersResponse{
+			Action: "getCustomers",
+			Status: true,
+			Data:   data,
+			Meta: &keymint.PaginationMeta{
+				Total:      len(matches),
+				Page:       page,
+				Limit:      limit,
+				TotalPages: (len(matches) + limit - 1) / limit,
+			},
+			Code: 0,
+		}, nil
+	})
+}
+
+// AllCustomers implements keymint.KeymintAPI. Like GetAllCustomers, it ignores RequestOptions.
+func (f *Fake) AllCustomers(ctx context.Context, params keymint.GetAllCustomersParams, opts ...keymint.PaginateOption) iter.Seq2[keymint.Customer, error] {
+	return keymint.Paginate(ctx, keymint.CustomerPages(f, params), opts...)
+}
+
+// GetCustomerWithKeys implements keymint.KeymintAPI.
+func (f *Fake) GetCustomerWithKeys(params keymint.GetCustomerWithKeysParams, opts ...*keymint.RequestOptions) (*keymint.GetCustomerWithKeysResponse, error) {
+	return f.GetCustomerWithKeysContext(context.Background(), params, opts...)
+}
+
+// GetCustomerWithKeysContext implements keymint.KeymintAPI.
+func (f *Fake) GetCustomerWithKeysContext(ctx context.Context, params keymint.GetCustomerWithKeysParams, _ ...*keymint.RequestOptions) (*keymint.GetCustomerWithKeysResponse, error) {
+	return call(f, ctx, "GetCustomerWithKeys", params, nil, func() (*keymint.GetCustomerWithKeysResponse, error) {
+		c, err := f.findCustomer(params.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &keymint.GetCustomerWithKeysResponse{
+			Action: "getCustomerWithKeys",
+			Status: true,
+			Code:   0,
+		}
+		res.Data.Customer = *c
+		res.Data.LicenseKeys = []keymint.CustomerLicenseKey{}
+		for _, l := range f.licenses {
+			if l.customerID == c.ID {
+				res.Data.LicenseKeys = append(res.Data.LicenseKeys, l.customerKey())
+			}
+		}
+		return res, nil
+	})
+}
+
+// UpdateCustomer implements keymint.KeymintAPI.
+func (f *Fake) UpdateCustomer(params keymint.UpdateCustomerParams, opts ...*keymint.RequestOptions) (*keymint.UpdateCustomerResponse, error) {
+	return f.UpdateCustomerContext(context.Background(), params, opts...)
+}
+
+// UpdateCustomerContext implements keymint.KeymintAPI.
+func (f *Fake) UpdateCustomerContext(ctx context.Context, params keymint.UpdateCustomerParams, opts ...*keymint.RequestOptions) (*keymint.UpdateCustomerResponse, error) {
+	return call(f, ctx, "UpdateCustomer", params, opts, func() (*keymint.UpdateCustomerResponse, error) {
+		c, err := f.findCustomer(params.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if params.Email != nil && *params.Email != c.Email {
+			if f.emailTaken(*params.Email) {
+				return nil, apiError(409, codeCustomerExists, "A customer with this email already exists")
+			}
+			c.Email = *params.Email
+		}
+		if params.Name != nil {
+			c.Name = *params.Name
+		}
+		if params.Active != nil {
+			c.Active = *params.Active
+		}
+		c.UpdatedAt = timestamp(f.now())
+		f.emit(EventCustomerUpdated, customerData(c.ID))
+
+		return &keymint.UpdateCustomerResponse{
+			Action:  "updateCustomer",
+			Status:  true,
+			Message: "Customer updated",
+			Data:    *c,
+			Code:    0,
+		}, nil
+	})
+}
+
+// DeleteCustomer implements keymint.KeymintAPI.
+func (f *Fake) DeleteCustomer(params keymint.DeleteCustomerParams, opts ...*keymint.RequestOptions) (*keymint.DeleteCustomerResponse, error) {
+	return f.DeleteCustomerContext(context.Background(), params, opts...)
+}
+
+// DeleteCustomerContext implements keymint.KeymintAPI.
+// The customer's license keys are kept, without a customer.
+func (f *Fake) DeleteCustomerContext(ctx context.Context, params keymint.DeleteCustomerParams, opts ...*keymint.RequestOptions) (*keymint.DeleteCustomerResponse, error) {
+	return call(f, ctx, "DeleteCustomer", params, opts, func() (*keymint.DeleteCustomerResponse, error) {
+		c, err := f.findCustomer(params.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, candidate := range f.customers {
+			if candidate == c {
+				f.customers = append(f.customers[:i:i], f.customers[i+1:]...)
+				break
+			}
+		}
+		for _, l := range f.licenses {
+			if l.customerID == c.ID {
+				l.customerID = ""
+			}
+		}
+		f.emit(EventCustomerDeleted, customerData(c.ID))
+
+		return &keymint.DeleteCustomerResponse{
+			Action:  "deleteCustomer",
+			Status:  true,
+			Message: "Customer deleted",
+			Code:    0,
+		}, nil
+	})
+}
+
+// ToggleCustomerStatus implements keymint.KeymintAPI.
+func (f *Fake) ToggleCustomerStatus(params keymint.ToggleCustomerStatusParams, opts ...*keymint.RequestOptions) (*keymint.ToggleCustomerStatusResponse, error) {
+	return f.ToggleCustomerStatusContext(context.Background(), params, opts...)
+}
+
+// ToggleCustomerStatusContext implements keymint.KeymintAPI.
+// While a customer is disabled, their license keys cannot be activated or checked out.
+func (f *Fake) ToggleCustomerStatusContext(ctx context.Context, params keymint.ToggleCustomerStatusParams, opts ...*keymint.RequestOptions) (*keymint.ToggleCustomerStatusResponse, error) {
+	return call(f, ctx, "ToggleCustomerStatus", params, opts, func() (*keymint.ToggleCustomerStatusResponse, error) {
+		c, err := f.findCustomer(params.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Active = !c.Active
+		c.UpdatedAt = timestamp(f.now())
+
+		event, message := EventCustomerDisabled, "Customer disabled"
+		if c.Active {
+			event, message = EventCustomerEnabled, "Customer enabled"
+		}
+		f.emit(event, customerData(c.ID))
+		return &keymint.ToggleCustomerStatusResponse{
+			Action:  "toggleActive",
+			Status:  true,
+			Message: message,
+			Code:    0,
+		}, nil
+	})
+}
+
+// GetCustomerById implements keymint.KeymintAPI.
+func (f *Fake) GetCustomerById(params keymint.GetCustomerByIdParams, opts ...*keymint.RequestOptions) (*keymint.GetCustomerByIdResponse, error) {
+	return f.GetCustomerByIdContext(context.Background(), params, opts...)
+}
+
+// GetCustomerByIdContext implements keymint.KeymintAPI.
+func (f *Fake) GetCustomerByIdContext(ctx context.Context, params keymint.GetCustomerByIdParams, _ ...*keymint.RequestOptions) (*keymint.GetCustomerByIdResponse, error) {
+	return call(f, ctx, "GetCustomerById", params, nil, func() (*keymint.GetCustomerByIdResponse, error) {
+		c, err := f.findCustomer(params.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &keymint.GetCustomerByIdResponse{
+			Action: "getCustomerById",
+			Status: true,
+			Data:   []keymint.Customer{*c},
+			Code:   0,
+		}, nil
+	})
+}
+
+// addCustomer creates an active customer. The email may be empty, but must be unique otherwise.
+// It must be called with f.mu held.
+func (f *Fake) addCustomer(name, email string) (*keymint.Customer, error) {
+	if name == "" {
+		return nil, missingParam("name")
+	}
+	if email != "" && f.emailTaken(email) {
+		return nil, apiError(409, codeCustomerExists, "A customer with this email already exists")
+	}
+
+	now := timestamp(f.now())
+	c := &keymint.Customer{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Email:     email,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+		CreatedBy: "keymintest",
+	}
+	f.customers = append(f.customers, c)
+	f.emit(EventCustomerCreated, customerData(c.ID))
+	return c, nil
+}
+
+// emailTaken reports whether a customer already uses email.
+// It must be called with f.mu held.
+func (f *Fake) emailTaken(email string) bool {
+	for _, c := range f.customers {
+		if c.Email == email {
+			return true
+		}
+	}
+	return false
+}
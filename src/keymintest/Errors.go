@@ -0,0 +1,49 @@
+package keymintest
+
+import (
+	"fmt"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// Error codes reported in keymint.ApiError.Code by the fake. They are the fake's own
+// numbering, not the API's: match failures with errors.Is and the keymint sentinel
+// errors rather than with codes.
+const (
+	// codeInvalidRequest indicates missing or malformed parameters.
+	codeInvalidRequest = 1
+	// codeKeyNotFound indicates the license key does not exist for the product.
+	codeKeyNotFound = 2
+	// codeKeyBlocked indicates the license key is blocked.
+	codeKeyBlocked = 3
+	// codeKeyExpired indicates the license key has expired.
+	codeKeyExpired = 4
+	// codeMaxActivations indicates the license key has no activation or floating seat left.
+	codeMaxActivations = 5
+	// codeHostNotAllowed indicates the host is not in the license key's allowed hosts.
+	codeHostNotAllowed = 6
+	// codeDeviceNotFound indicates the device is not activated on the license key.
+	codeDeviceNotFound = 7
+	// codeCustomerNotFound indicates the customer does not exist.
+	codeCustomerNotFound = 8
+	// codeCustomerDisabled indicates the customer owning the license key is disabled.
+	codeCustomerDisabled = 9
+	// codeCustomerExists indicates a customer with the same email already exists.
+	codeCustomerExists = 10
+	// codeSessionNotFound indicates the floating session does not exist or expired.
+	codeSessionNotFound = 11
+	// codeInvalidSignature indicates the floating session nonce or signature is wrong.
+	codeInvalidSignature = 12
+	// codeInvalidAPIKey indicates the request did not carry the API key required by RequireAPIKey.
+	codeInvalidAPIKey = 13
+)
+
+// apiError returns an ApiError as reported by the API.
+func apiError(status, code int, message string) *keymint.ApiError {
+	return &keymint.ApiError{Message: message, Code: code, Status: &status}
+}
+
+// missingParam returns the ApiError reported for a missing required parameter.
+func missingParam(name string) *keymint.ApiError {
+	return apiError(400, codeInvalidRequest, fmt.Sprintf("%s is required", name))
+}
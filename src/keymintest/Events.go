@@ -0,0 +1,89 @@
+package keymintest
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types reported to the hook set with WithEventHook.
+const (
+	EventKeyCreated        = "key.created"
+	EventKeyActivated      = "key.activated"
+	EventKeyDeactivated    = "key.deactivated"
+	EventKeyBlocked        = "key.blocked"
+	EventKeyUnblocked      = "key.unblocked"
+	EventCustomerCreated   = "customer.created"
+	EventCustomerUpdated   = "customer.updated"
+	EventCustomerDeleted   = "customer.deleted"
+	EventCustomerEnabled   = "customer.enabled"
+	EventCustomerDisabled  = "customer.disabled"
+	EventSessionCheckedOut = "session.checked_out"
+	EventSessionCheckedIn  = "session.checked_in"
+	EventSessionExpired    = "session.expired"
+)
+
+// Event describes a change of the fake's state, e.g. to deliver it as a webhook.
+type Event struct {
+	// ID is the unique event ID.
+	ID string `json:"id"`
+	// Type is the event type (e.g. EventKeyActivated).
+	Type string `json:"type"`
+	// Created is when the event occurred, according to the fake's clock.
+	Created time.Time `json:"created"`
+	// Data describes the affected resource (e.g. productId, licenseKey, hostId).
+	Data map[string]interface{} `json:"data"`
+}
+
+// WithEventHook calls hook with every change of the fake's state, in order. The hook is
+// called synchronously once the change is done, outside of the fake's lock.
+func WithEventHook(hook func(Event)) Option {
+	return func(f *Fake) {
+		f.onEvent = hook
+	}
+}
+
+// emit queues an event, delivered by end.
+// It must be called with f.mu held.
+func (f *Fake) emit(eventType string, data map[string]interface{}) {
+	if f.onEvent == nil {
+		return
+	}
+	f.pending = append(f.pending, Event{
+		ID:      uuid.NewString(),
+		Type:    eventType,
+		Created: f.now().UTC(),
+		Data:    data,
+	})
+}
+
+// end is deferred by every call, which locks the fake and queues its events in pending:
+// it unlocks the fake, then delivers the queued events in order to the OnEvent callback.
+// Delivering them unlocked lets the callback call the fake without deadlocking.
+func (f *Fake) end() {
+	events := f.pending
+	f.pending = nil
+	f.mu.Unlock()
+
+	for _, event := range events {
+		f.onEvent(event)
+	}
+}
+
+// keyData returns the event data describing a license key.
+func keyData(l *license) map[string]interface{} {
+	return map[string]interface{}{"productId": l.productID, "licenseKey": l.key}
+}
+
+// sessionData returns the event data describing a floating session.
+func sessionData(s *session) map[string]interface{} {
+	data := keyData(s.license)
+	data["sessionId"] = s.id
+	data["hostId"] = s.hostID
+	return data
+}
+
+// customerData returns the event data describing a customer.
+func customerData(id string) map[string]interface{} {
+	return map[string]interface{}{"customerId": id}
+}
@@ -0,0 +1,309 @@
+// Package keymintest provides an in-memory fake of the Keymint API for unit tests.
+//
+// A Fake implements keymint.KeymintAPI, so code depending on that interface can be
+// tested without the network:
+//
+//	fake := keymintest.New()
+//	key, _ := fake.CreateKey(keymint.CreateKeyParams{ProductID: "prod_1"})
+//	_, err := fake.ActivateKey(keymint.ActivateKeyParams{ProductID: "prod_1", LicenseKey: key.Key})
+//
+// The fake keeps customers, license keys, device activations, allowed hosts, blocked
+// status and floating sessions in memory, and enforces them like the API does. Failures
+// are returned as *keymint.ApiError values carrying the HTTP status and message of the
+// API, so errors.Is works with the keymint sentinel errors (keymint.ErrKeyBlocked, ...).
+// Like keymint.Client, the fake honors the idempotency key of mutating calls.
+package keymintest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// defaultHeartbeatInterval is the floating license heartbeat interval, unless overridden with WithHeartbeatInterval.
+const defaultHeartbeatInterval = time.Minute
+
+// Fake is an in-memory implementation of keymint.KeymintAPI. It is safe for concurrent use.
+type Fake struct {
+	mu                sync.Mutex
+	now               func() time.Time
+	heartbeatInterval time.Duration
+
+	customers []*keymint.Customer
+	licenses  []*license
+	sessions  map[string]*session
+
+	errors     map[string]error
+	calls      map[string]int
+	idempotent map[string]interface{} // responses of the mutating calls, by "operation key"
+
+	onEvent func(Event)
+	pending []Event
+}
+
+// license is a license key stored by the fake.
+type license struct {
+	id             string
+	key            string
+	productID      string
+	maxActivations int
+	expiresAt      keymint.Time // zero if the key never expires
+	customerID     string
+	versionID      *string
+	metadata       map[string]interface{}
+	allowedHosts   []string
+	blocked        bool
+	devices        []keymint.DeviceDetails
+}
+
+// session is a floating license session stored by the fake.
+type session struct {
+	id        string
+	secret    string
+	nonce     string
+	license   *license
+	hostID    string
+	expiresAt time.Time
+}
+
+// Option configures a Fake.
+type Option func(*Fake)
+
+// WithClock sets the function the fake reads the current time from, e.g. to test
+// key expiry or floating session timeouts without sleeping.
+func WithClock(now func() time.Time) Option {
+	return func(f *Fake) {
+		f.now = now
+	}
+}
+
+// WithHeartbeatInterval sets the floating license heartbeat interval (defaults to 1 minute).
+// A session expires when it missed two heartbeats.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(f *Fake) {
+		f.heartbeatInterval = interval
+	}
+}
+
+// New creates an empty fake.
+func New(opts ...Option) *Fake {
+	f := &Fake{
+		now:               time.Now,
+		heartbeatInterval: defaultHeartbeatInterval,
+		sessions:          make(map[string]*session),
+		errors:            make(map[string]error),
+		calls:             make(map[string]int),
+		idempotent:        make(map[string]interface{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(f)
+		}
+	}
+	return f
+}
+
+// Fake implements keymint.KeymintAPI.
+var _ keymint.KeymintAPI = (*Fake)(nil)
+
+// SetError makes every call to operation, identified by its method name (e.g. "ActivateKey"),
+// fail with err without touching the fake's state. Pass a nil err to clear it.
+func (f *Fake) SetError(operation string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errors, operation)
+		return
+	}
+	f.errors[operation] = err
+}
+
+// Calls returns the number of calls made to operation, identified by its method name (e.g. "ActivateKey").
+func (f *Fake) Calls(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[operation]
+}
+
+// call records a call to operation and runs handle with the fake locked, returning its
+// response like keymint.Client does: the call fails with the keymint.ValidateParams error
+// if params are invalid, with a keymint.ContextError if ctx is done, or with the error set
+// with SetError, and failures still return a non-nil response.
+//
+// opts are the RequestOptions of a mutating call, or nil for reads. Like the API, a call
+// reusing the idempotency key of a successful call to the same operation is not run again:
+// it returns the response of the first call.
+func call[R any](f *Fake, ctx context.Context, operation string, params interface{}, opts []*keymint.RequestOptions, handle func() (*R, error)) (*R, error) {
+	f.mu.Lock()
+	defer f.end()
+	f.calls[operation]++
+
+	if err := keymint.ValidateParams(params); err != nil {
+		return new(R), err
+	}
+	if err := ctx.Err(); err != nil {
+		return new(R), keymint.ContextError(err)
+	}
+	if err := f.errors[operation]; err != nil {
+		return new(R), err
+	}
+
+	key := idempotencyKey(opts)
+	if cached, ok := f.idempotent[operation+" "+key]; ok && key != "" {
+		res := *cached.(*R)
+		return &res, nil
+	}
+
+	res, err := handle()
+	if err != nil {
+		return new(R), err
+	}
+	if key != "" {
+		f.idempotent[operation+" "+key] = res
+	}
+	replay := *res
+	return &replay, nil
+}
+
+// idempotencyKey returns the idempotency key set in opts, or an empty string if none is.
+func idempotencyKey(opts []*keymint.RequestOptions) string {
+	key := ""
+	for _, opt := range opts {
+		if opt != nil && opt.IdempotencyKey != "" {
+			key = opt.IdempotencyKey
+		}
+	}
+	return key
+}
+
+// findLicense returns the license key of the given product, or a not-found ApiError.
+// It must be called with f.mu held.
+func (f *Fake) findLicense(productID, key string) (*license, error) {
+	if productID == "" {
+		return nil, missingParam("productId")
+	}
+	if key == "" {
+		return nil, missingParam("licenseKey")
+	}
+	for _, l := range f.licenses {
+		if l.key == key && l.productID == productID {
+			return l, nil
+		}
+	}
+	return nil, apiError(404, codeKeyNotFound, "License key not found")
+}
+
+// findCustomer returns a customer by ID, or a not-found ApiError.
+// It must be called with f.mu held.
+func (f *Fake) findCustomer(id string) (*keymint.Customer, error) {
+	if id == "" {
+		return nil, missingParam("customerId")
+	}
+	for _, c := range f.customers {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, apiError(404, codeCustomerNotFound, "Customer not found")
+}
+
+// customerOf returns the customer the license key belongs to, or nil.
+// It must be called with f.mu held.
+func (f *Fake) customerOf(l *license) *keymint.Customer {
+	if l.customerID == "" {
+		return nil
+	}
+	c, _ := f.findCustomer(l.customerID)
+	return c
+}
+
+// checkUsable returns an ApiError if the license key cannot be used on hostID:
+// it is blocked or expired, its customer is disabled, or hostID is not allowed.
+// It must be called with f.mu held.
+func (f *Fake) checkUsable(l *license, hostID string) error {
+	if l.blocked {
+		return apiError(403, codeKeyBlocked, "License key is blocked")
+	}
+	if !l.expiresAt.IsZero() && !f.now().Before(l.expiresAt.Time) {
+		return apiError(403, codeKeyExpired, "License key has expired")
+	}
+	if c := f.customerOf(l); c != nil && !c.Active {
+		return apiError(403, codeCustomerDisabled, "Customer is disabled")
+	}
+	if len(l.allowedHosts) > 0 && hostID != "" && !contains(l.allowedHosts, hostID) {
+		return apiError(403, codeHostNotAllowed, "Host is not allowed for this license key")
+	}
+	return nil
+}
+
+// timestamp returns t the way the API reports it (UTC, millisecond precision).
+func timestamp(t time.Time) keymint.Time {
+	return keymint.NewTime(t.UTC().Truncate(time.Millisecond))
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newLicenseKey generates a license key such as "ABCDE-FGHIJ-KLMNO-PQRST".
+func newLicenseKey() string {
+	text := rand.Text()
+	return strings.Join([]string{text[0:5], text[5:10], text[10:15], text[15:20]}, "-")
+}
+
+// newSessionID generates a 22-character floating session ID.
+func newSessionID() string {
+	return rand.Text()[:22]
+}
+
+// contains reports whether values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneMap returns a shallow copy of m, or nil if m is empty.
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// cloneStrings returns a copy of values, or nil if values is empty.
+func cloneStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
+
+// stringPtr returns a pointer to a copy of s.
+func stringPtr(s string) *string {
+	return &s
+}
+
+// cloneString returns a pointer to a copy of *s, or nil if s is nil.
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	return stringPtr(*s)
+}
@@ -0,0 +1,41 @@
+package keymintest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+	"github.com/keymint-dev/keymint-go/src/keymintest"
+)
+
+func TestFakeValidatesParamsLikeClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the client sent invalid params")
+	}))
+	defer server.Close()
+	client, err := keymint.New("test_key", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := keymintest.New()
+	params := keymint.CreateCustomerParams{Name: "Ada", Email: "not an email"}
+	res, fakeErr := fake.CreateCustomer(params)
+	_, clientErr := client.CreateCustomer(params)
+
+	if !errors.Is(fakeErr, keymint.ErrValidation) {
+		t.Fatalf("got error %v, want ErrValidation", fakeErr)
+	}
+	if !reflect.DeepEqual(fakeErr, clientErr) {
+		t.Errorf("the fake returned %#v, the client %#v", fakeErr, clientErr)
+	}
+	if res == nil {
+		t.Error("the failed call returned a nil response")
+	}
+	if n := fake.Calls("CreateCustomer"); n != 1 {
+		t.Errorf("got %d calls, want 1", n)
+	}
+}
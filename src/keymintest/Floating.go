@@ -0,0 +1,165 @@
+package keymintest
+
+import (
+	"context"
+	"crypto/hmac"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// FloatingCheckout implements keymint.KeymintAPI.
+func (f *Fake) FloatingCheckout(params keymint.FloatingCheckoutParams, opts ...*keymint.RequestOptions) (*keymint.FloatingCheckoutResponse, error) {
+	return f.FloatingCheckoutContext(context.Background(), params, opts...)
+}
+
+// FloatingCheckoutContext implements keymint.KeymintAPI.
+// The key's MaxActivations is its number of floating seats (0 for unlimited). Checking out
+// again from a host that holds a session replaces that session.
+func (f *Fake) FloatingCheckoutContext(ctx context.Context, params keymint.FloatingCheckoutParams, opts ...*keymint.RequestOptions) (*keymint.FloatingCheckoutResponse, error) {
+	return call(f, ctx, "FloatingCheckout", params, opts, func() (*keymint.FloatingCheckoutResponse, error) {
+		l, err := f.findLicense(params.ProductID, params.LicenseKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.checkUsable(l, params.HostID); err != nil {
+			return nil, err
+		}
+
+		f.expireSessions()
+		sessions := 0
+		for id, s := range f.sessions {
+			switch {
+			case s.license != l:
+			case s.hostID == params.HostID:
+				delete(f.sessions, id)
+			default:
+				sessions++
+			}
+		}
+		if l.maxActivations > 0 && sessions >= l.maxActivations {
+			return nil, apiError(403, codeMaxActivations, "Maximum activations reached for this license key")
+		}
+
+		s := &session{
+			id:        newSessionID(),
+			secret:    randomHex(32),
+			nonce:     randomHex(16),
+			license:   l,
+			hostID:    params.HostID,
+			expiresAt: f.now().Add(2 * f.heartbeatInterval),
+		}
+		f.sessions[s.id] = s
+		f.emit(EventSessionCheckedOut, sessionData(s))
+		sessions++
+
+		res := &keymint.FloatingCheckoutResponse{
+			Code:              0,
+			Message:           "License checked out",
+			SessionID:         s.id,
+			SessionSecret:     s.secret,
+			NextNonce:         keymint.Nonce{Value: s.nonce},
+			ExpiresAt:         timestamp(s.expiresAt),
+			HeartbeatInterval: int(f.heartbeatInterval.Seconds()),
+			Metadata:          cloneMap(l.metadata),
+			CurrentSessions:   &sessions,
+		}
+		if l.maxActivations > 0 {
+			maxSessions := l.maxActivations
+			res.MaxSessions = &maxSessions
+		}
+		if c := f.customerOf(l); c != nil {
+			res.LicenseeName = stringPtr(c.Name)
+			res.LicenseeEmail = stringPtr(c.Email)
+		}
+		return res, nil
+	})
+}
+
+// FloatingHeartbeat implements keymint.KeymintAPI.
+func (f *Fake) FloatingHeartbeat(params keymint.FloatingHeartbeatParams, opts ...*keymint.RequestOptions) (*keymint.FloatingHeartbeatResponse, error) {
+	return f.FloatingHeartbeatContext(context.Background(), params, opts...)
+}
+
+// FloatingHeartbeatContext implements keymint.KeymintAPI.
+// The request must be signed with keymint.GenerateSessionSignature over the nonce returned
+// by the previous checkout or heartbeat; every heartbeat rotates the nonce.
+func (f *Fake) FloatingHeartbeatContext(ctx context.Context, params keymint.FloatingHeartbeatParams, opts ...*keymint.RequestOptions) (*keymint.FloatingHeartbeatResponse, error) {
+	return call(f, ctx, "FloatingHeartbeat", params, opts, func() (*keymint.FloatingHeartbeatResponse, error) {
+		s, err := f.verifySession(params.ProductID, params.LicenseKey, params.SessionID, params.Timestamp, params.Signature)
+		if err != nil {
+			return nil, err
+		}
+
+		s.nonce = randomHex(16)
+		s.expiresAt = f.now().Add(2 * f.heartbeatInterval)
+
+		return &keymint.FloatingHeartbeatResponse{
+			Code:      0,
+			Message:   "Heartbeat accepted",
+			ExpiresAt: timestamp(s.expiresAt),
+			NextNonce: keymint.Nonce{Value: s.nonce},
+		}, nil
+	})
+}
+
+// FloatingCheckin implements keymint.KeymintAPI.
+func (f *Fake) FloatingCheckin(params keymint.FloatingCheckinParams, opts ...*keymint.RequestOptions) (*keymint.FloatingCheckinResponse, error) {
+	return f.FloatingCheckinContext(context.Background(), params, opts...)
+}
+
+// FloatingCheckinContext implements keymint.KeymintAPI.
+// The request is signed like a heartbeat.
+func (f *Fake) FloatingCheckinContext(ctx context.Context, params keymint.FloatingCheckinParams, opts ...*keymint.RequestOptions) (*keymint.FloatingCheckinResponse, error) {
+	return call(f, ctx, "FloatingCheckin", params, opts, func() (*keymint.FloatingCheckinResponse, error) {
+		s, err := f.verifySession(params.ProductID, params.LicenseKey, params.SessionID, params.Timestamp, params.Signature)
+		if err != nil {
+			return nil, err
+		}
+
+		delete(f.sessions, s.id)
+		f.emit(EventSessionCheckedIn, sessionData(s))
+		return &keymint.FloatingCheckinResponse{Code: 0, Message: "License checked in"}, nil
+	})
+}
+
+// verifySession returns the live session of a heartbeat or checkin request, after checking
+// its nonce and signature. A session whose key can no longer be used is ended.
+// It must be called with f.mu held.
+func (f *Fake) verifySession(productID, key, sessionID string, nonce keymint.Nonce, signature string) (*session, error) {
+	l, err := f.findLicense(productID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	f.expireSessions()
+	s, ok := f.sessions[sessionID]
+	if !ok || s.license != l {
+		return nil, apiError(404, codeSessionNotFound, "Session not found")
+	}
+
+	if nonce.Value != s.nonce {
+		return nil, apiError(403, codeInvalidSignature, "Invalid or reused nonce")
+	}
+	expected := keymint.GenerateSessionSignature(s.id, s.nonce, s.secret)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, apiError(403, codeInvalidSignature, "Invalid session signature")
+	}
+
+	if err := f.checkUsable(l, s.hostID); err != nil {
+		delete(f.sessions, s.id)
+		return nil, err
+	}
+	return s, nil
+}
+
+// expireSessions ends the sessions that missed their heartbeats.
+// It must be called with f.mu held.
+func (f *Fake) expireSessions() {
+	now := f.now()
+	for id, s := range f.sessions {
+		if !now.Before(s.expiresAt) {
+			delete(f.sessions, id)
+			f.emit(EventSessionExpired, sessionData(s))
+		}
+	}
+}
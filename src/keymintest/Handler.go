@@ -0,0 +1,163 @@
+package keymintest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// route serves one API endpoint from the fake.
+type route struct {
+	// operation is the name of the keymint.Client method calling the endpoint.
+	operation string
+	// handle decodes the request, calls the fake and returns the response to encode.
+	handle func(f *Fake, r *http.Request) (interface{}, error)
+}
+
+// routes maps "METHOD /path" to the endpoints of the API, as called by keymint.Client.
+var routes = map[string]route{
+	"POST /key":              bodyRoute("CreateKey", (*Fake).CreateKeyContext),
+	"GET /key":               queryRoute("GetKey", (*Fake).GetKeyContext, getKeyParams),
+	"POST /key/activate":     bodyRoute("ActivateKey", (*Fake).ActivateKeyContext),
+	"POST /key/deactivate":   bodyRoute("DeactivateKey", (*Fake).DeactivateKeyContext),
+	"POST /key/checkout":     bodyRoute("FloatingCheckout", (*Fake).FloatingCheckoutContext),
+	"POST /key/heartbeat":    bodyRoute("FloatingHeartbeat", (*Fake).FloatingHeartbeatContext),
+	"POST /key/checkin":      bodyRoute("FloatingCheckin", (*Fake).FloatingCheckinContext),
+	"POST /key/block":        bodyRoute("BlockKey", (*Fake).BlockKeyContext),
+	"POST /key/unblock":      bodyRoute("UnblockKey", (*Fake).UnblockKeyContext),
+	"POST /customer":         bodyRoute("CreateCustomer", (*Fake).CreateCustomerContext),
+	"GET /customer":          queryRoute("GetAllCustomers", (*Fake).GetAllCustomersContext, getAllCustomersParams),
+	"GET /customer/keys":     queryRoute("GetCustomerWithKeys", (*Fake).GetCustomerWithKeysContext, customerQuery[keymint.GetCustomerWithKeysParams]),
+	"GET /customer/by-id":    queryRoute("GetCustomerById", (*Fake).GetCustomerByIdContext, customerQuery[keymint.GetCustomerByIdParams]),
+	"PUT /customer/by-id":    bodyRoute("UpdateCustomer", (*Fake).UpdateCustomerContext),
+	"DELETE /customer/by-id": queryRoute("DeleteCustomer", (*Fake).DeleteCustomerContext, customerQuery[keymint.DeleteCustomerParams]),
+	"POST /customer/disable": bodyRoute("ToggleCustomerStatus", (*Fake).ToggleCustomerStatusContext),
+}
+
+// fakeMethod is the signature of the fake's Context methods.
+type fakeMethod[P, R any] func(f *Fake, ctx context.Context, params P, opts ...*keymint.RequestOptions) (*R, error)
+
+// bodyRoute serves an endpoint taking its params as a JSON request body.
+func bodyRoute[P, R any](operation string, method fakeMethod[P, R]) route {
+	return route{operation: operation, handle: func(f *Fake, r *http.Request) (interface{}, error) {
+		var params P
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			return nil, apiError(400, codeInvalidRequest, "Invalid JSON body")
+		}
+		res, err := method(f, r.Context(), params, requestOptions(r))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}}
+}
+
+// queryRoute serves an endpoint taking its params from the query string.
+func queryRoute[P, R any](operation string, method fakeMethod[P, R], parse func(r *http.Request) (P, error)) route {
+	return route{operation: operation, handle: func(f *Fake, r *http.Request) (interface{}, error) {
+		params, err := parse(r)
+		if err != nil {
+			return nil, err
+		}
+		res, err := method(f, r.Context(), params, requestOptions(r))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}}
+}
+
+// requestOptions returns the RequestOptions the fake's methods read from the headers of r.
+func requestOptions(r *http.Request) *keymint.RequestOptions {
+	return &keymint.RequestOptions{IdempotencyKey: r.Header.Get("Idempotency-Key")}
+}
+
+// getKeyParams reads the query string of GetKey.
+func getKeyParams(r *http.Request) (keymint.GetKeyParams, error) {
+	query := r.URL.Query()
+	return keymint.GetKeyParams{ProductID: query.Get("productId"), LicenseKey: query.Get("licenseKey")}, nil
+}
+
+// getAllCustomersParams reads the query string of GetAllCustomers.
+func getAllCustomersParams(r *http.Request) (keymint.GetAllCustomersParams, error) {
+	var params keymint.GetAllCustomersParams
+	query := r.URL.Query()
+
+	for name, field := range map[string]**int{"page": &params.Page, "limit": &params.Limit} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return params, apiError(400, codeInvalidRequest, name+" must be an integer")
+			}
+			*field = &n
+		}
+	}
+	if email := query.Get("email"); email != "" {
+		params.Email = &email
+	}
+	return params, nil
+}
+
+// customerQuery reads the customerId query parameter of the customer endpoints.
+func customerQuery[P ~struct {
+	CustomerID string `json:"customerId"`
+}](r *http.Request) (P, error) {
+	return P{CustomerID: r.URL.Query().Get("customerId")}, nil
+}
+
+// operationOf returns the name of the keymint.Client method calling the endpoint of r,
+// or an empty string if r does not target an API endpoint.
+func operationOf(r *http.Request) string {
+	return routes[r.Method+" "+r.URL.Path].operation
+}
+
+// ServeHTTP serves the Keymint API from the fake's state, with the JSON shapes of the API.
+// It does not check the API key; Server does.
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Request-Id", "req_"+randomHex(8))
+
+	route, ok := routes[r.Method+" "+r.URL.Path]
+	if !ok {
+		writeError(w, apiError(404, codeInvalidRequest, "Route not found"))
+		return
+	}
+
+	res, err := route.handle(f, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// errorBody is the JSON body of an API error response.
+type errorBody struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// writeError writes err as an API error response. An ApiError is written with its status,
+// message and code; any other error is written as a 500.
+func writeError(w http.ResponseWriter, err error) {
+	status, body := http.StatusInternalServerError, errorBody{Message: err.Error(), Code: -1}
+
+	var apiErr *keymint.ApiError
+	if errors.As(err, &apiErr) {
+		status, body = http.StatusBadRequest, errorBody{Message: apiErr.Message, Code: apiErr.Code}
+		if apiErr.Status != nil {
+			status = *apiErr.Status
+		}
+	}
+	writeJSON(w, status, body)
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
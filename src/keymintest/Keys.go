@@ -0,0 +1,256 @@
+package keymintest
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// CreateKey implements keymint.KeymintAPI.
+func (f *Fake) CreateKey(params keymint.CreateKeyParams, opts ...*keymint.RequestOptions) (*keymint.CreateKeyResponse, error) {
+	return f.CreateKeyContext(context.Background(), params, opts...)
+}
+
+// CreateKeyContext implements keymint.KeymintAPI.
+// A MaxActivations of 0, or none, allows unlimited activations.
+func (f *Fake) CreateKeyContext(ctx context.Context, params keymint.CreateKeyParams, opts ...*keymint.RequestOptions) (*keymint.CreateKeyResponse, error) {
+	return call(f, ctx, "CreateKey", params, opts, func() (*keymint.CreateKeyResponse, error) {
+		l := &license{
+			id:           uuid.NewString(),
+			key:          newLicenseKey(),
+			productID:    params.ProductID,
+			versionID:    cloneString(params.VersionID),
+			metadata:     cloneMap(params.Metadata),
+			allowedHosts: cloneStrings(params.AllowedHosts),
+		}
+
+		if params.MaxActivations != nil {
+			if *params.MaxActivations < 0 {
+				return nil, apiError(400, codeInvalidRequest, "maxActivations must be a non-negative integer")
+			}
+			l.maxActivations = *params.MaxActivations
+		}
+		if !params.ExpiryDate.IsZero() {
+			l.expiresAt = timestamp(params.ExpiryDate.Time)
+		}
+
+		switch {
+		case params.CustomerID != nil && params.NewCustomer != nil:
+			return nil, apiError(400, codeInvalidRequest, "customerId and newCustomer are mutually exclusive")
+		case params.CustomerID != nil:
+			if _, err := f.findCustomer(*params.CustomerID); err != nil {
+				return nil, err
+			}
+			l.customerID = *params.CustomerID
+		case params.NewCustomer != nil:
+			email := ""
+			if params.NewCustomer.Email != nil {
+				email = *params.NewCustomer.Email
+			}
+			c, err := f.addCustomer(params.NewCustomer.Name, email)
+			if err != nil {
+				return nil, err
+			}
+			l.customerID = c.ID
+		}
+
+		f.licenses = append(f.licenses, l)
+		f.emit(EventKeyCreated, keyData(l))
+		return &keymint.CreateKeyResponse{Code: 0, Key: l.key}, nil
+	})
+}
+
+// ActivateKey implements keymint.KeymintAPI.
+func (f *Fake) ActivateKey(params keymint.ActivateKeyParams, opts ...*keymint.RequestOptions) (*keymint.ActivateKeyResponse, error) {
+	return f.ActivateKeyContext(context.Background(), params, opts...)
+}
+
+// ActivateKeyContext implements keymint.KeymintAPI.
+// Like the API, it generates a random host ID when params.HostID is nil, and
+// activating a host that is already activated does not use another activation.
+func (f *Fake) ActivateKeyContext(ctx context.Context, params keymint.ActivateKeyParams, opts ...*keymint.RequestOptions) (*keymint.ActivateKeyResponse, error) {
+	return call(f, ctx, "ActivateKey", params, opts, func() (*keymint.ActivateKeyResponse, error) {
+		l, err := f.findLicense(params.ProductID, params.LicenseKey)
+		if err != nil {
+			return nil, err
+		}
+
+		hostID := uuid.NewString()
+		if params.HostID != nil && *params.HostID != "" {
+			hostID = *params.HostID
+		}
+		if err := f.checkUsable(l, hostID); err != nil {
+			return nil, err
+		}
+
+		activated := false
+		for i := range l.devices {
+			if l.devices[i].HostID == hostID {
+				if params.DeviceTag != nil {
+					l.devices[i].DeviceTag = cloneString(params.DeviceTag)
+				}
+				activated = true
+			}
+		}
+		if !activated {
+			if l.maxActivations > 0 && len(l.devices) >= l.maxActivations {
+				return nil, apiError(403, codeMaxActivations, "Maximum activations reached for this license key")
+			}
+			l.devices = append(l.devices, keymint.DeviceDetails{
+				HostID:         hostID,
+				DeviceTag:      cloneString(params.DeviceTag),
+				ActivationTime: timestamp(f.now()),
+			})
+
+			data := keyData(l)
+			data["hostId"] = hostID
+			f.emit(EventKeyActivated, data)
+		}
+
+		res := &keymint.ActivateKeyResponse{
+			Code:         0,
+			Message:      "License valid",
+			Metadata:     cloneMap(l.metadata),
+			VersionID:    cloneString(l.versionID),
+			AllowedHosts: cloneStrings(l.allowedHosts),
+		}
+		if c := f.customerOf(l); c != nil {
+			res.LicenseeName = stringPtr(c.Name)
+			res.LicenseeEmail = stringPtr(c.Email)
+		}
+		return res, nil
+	})
+}
+
+// DeactivateKey implements keymint.KeymintAPI.
+func (f *Fake) DeactivateKey(params keymint.DeactivateKeyParams, opts ...*keymint.RequestOptions) (*keymint.DeactivateKeyResponse, error) {
+	return f.DeactivateKeyContext(context.Background(), params, opts...)
+}
+
+// DeactivateKeyContext implements keymint.KeymintAPI.
+func (f *Fake) DeactivateKeyContext(ctx context.Context, params keymint.DeactivateKeyParams, opts ...*keymint.RequestOptions) (*keymint.DeactivateKeyResponse, error) {
+	return call(f, ctx, "DeactivateKey", params, opts, func() (*keymint.DeactivateKeyResponse, error) {
+		l, err := f.findLicense(params.ProductID, params.LicenseKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if params.HostID == nil {
+			l.devices = nil
+			f.emit(EventKeyDeactivated, keyData(l))
+			return &keymint.DeactivateKeyResponse{Code: 0, Message: "All devices deactivated"}, nil
+		}
+
+		for i, device := range l.devices {
+			if device.HostID == *params.HostID {
+				l.devices = append(l.devices[:i:i], l.devices[i+1:]...)
+
+				data := keyData(l)
+				data["hostId"] = device.HostID
+				f.emit(EventKeyDeactivated, data)
+				return &keymint.DeactivateKeyResponse{Code: 0, Message: "Device deactivated"}, nil
+			}
+		}
+		return nil, apiError(404, codeDeviceNotFound, "Device not found")
+	})
+}
+
+// GetKey implements keymint.KeymintAPI.
+func (f *Fake) GetKey(params keymint.GetKeyParams, opts ...*keymint.RequestOptions) (*keymint.GetKeyResponse, error) {
+	return f.GetKeyContext(context.Background(), params, opts...)
+}
+
+// GetKeyContext implements keymint.KeymintAPI.
+func (f *Fake) GetKeyContext(ctx context.Context, params keymint.GetKeyParams, _ ...*keymint.RequestOptions) (*keymint.GetKeyResponse, error) {
+	return call(f, ctx, "GetKey", params, nil, func() (*keymint.GetKeyResponse, error) {
+		l, err := f.findLicense(params.ProductID, params.LicenseKey)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &keymint.GetKeyResponse{Code: 0}
+		res.Data.License = keymint.LicenseDetails{
+			ID:             l.id,
+			Key:            l.key,
+			ProductID:      l.productID,
+			MaxActivations: l.maxActivations,
+			Activations:    len(l.devices),
+			Devices:        append([]keymint.DeviceDetails{}, l.devices...),
+			Activated:      len(l.devices) > 0,
+			ExpirationDate: l.expiresAt,
+			Metadata:       cloneMap(l.metadata),
+			VersionID:      cloneString(l.versionID),
+			AllowedHosts:   cloneStrings(l.allowedHosts),
+		}
+		if c := f.customerOf(l); c != nil {
+			res.Data.Customer = &keymint.CustomerDetails{
+				ID:     c.ID,
+				Name:   stringPtr(c.Name),
+				Email:  stringPtr(c.Email),
+				Active: c.Active,
+			}
+		}
+		return res, nil
+	})
+}
+
+// BlockKey implements keymint.KeymintAPI.
+func (f *Fake) BlockKey(params keymint.BlockKeyParams, opts ...*keymint.RequestOptions) (*keymint.BlockKeyResponse, error) {
+	return f.BlockKeyContext(context.Background(), params, opts...)
+}
+
+// BlockKeyContext implements keymint.KeymintAPI.
+// Blocking a key also ends its floating sessions.
+func (f *Fake) BlockKeyContext(ctx context.Context, params keymint.BlockKeyParams, opts ...*keymint.RequestOptions) (*keymint.BlockKeyResponse, error) {
+	return call(f, ctx, "BlockKey", params, opts, func() (*keymint.BlockKeyResponse, error) {
+		l, err := f.findLicense(params.ProductID, params.LicenseKey)
+		if err != nil {
+			return nil, err
+		}
+
+		l.blocked = true
+		for id, s := range f.sessions {
+			if s.license == l {
+				delete(f.sessions, id)
+			}
+		}
+		f.emit(EventKeyBlocked, keyData(l))
+		return &keymint.BlockKeyResponse{Code: 0, Message: "Key blocked"}, nil
+	})
+}
+
+// UnblockKey implements keymint.KeymintAPI.
+func (f *Fake) UnblockKey(params keymint.UnblockKeyParams, opts ...*keymint.RequestOptions) (*keymint.UnblockKeyResponse, error) {
+	return f.UnblockKeyContext(context.Background(), params, opts...)
+}
+
+// UnblockKeyContext implements keymint.KeymintAPI.
+func (f *Fake) UnblockKeyContext(ctx context.Context, params keymint.UnblockKeyParams, opts ...*keymint.RequestOptions) (*keymint.UnblockKeyResponse, error) {
+	return call(f, ctx, "UnblockKey", params, opts, func() (*keymint.UnblockKeyResponse, error) {
+		l, err := f.findLicense(params.ProductID, params.LicenseKey)
+		if err != nil {
+			return nil, err
+		}
+
+		l.blocked = false
+		f.emit(EventKeyUnblocked, keyData(l))
+		return &keymint.UnblockKeyResponse{Code: 0, Message: "Key unblocked"}, nil
+	})
+}
+
+// customerKey returns l as listed in a customer's license keys.
+func (l *license) customerKey() keymint.CustomerLicenseKey {
+	return keymint.CustomerLicenseKey{
+		ID:             l.id,
+		Key:            l.key,
+		ProductID:      l.productID,
+		MaxActivations: l.maxActivations,
+		Activations:    len(l.devices),
+		Activated:      len(l.devices) > 0,
+		ExpirationDate: l.expiresAt,
+		Metadata:       cloneMap(l.metadata),
+		VersionID:      cloneString(l.versionID),
+		AllowedHosts:   cloneStrings(l.allowedHosts),
+	}
+}
@@ -0,0 +1,227 @@
+package keymintest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// APIKey is the API key accepted by Server. Requests carrying another key are rejected
+// with a 401, like the API does.
+const APIKey = "km_test_keymintest"
+
+// Server is a fake Keymint API served over HTTP by an httptest.Server, backed by a Fake.
+// It exercises the whole request path of keymint.Client (headers, retries, decoding, ...),
+// and lets tests script faults with Inject.
+//
+//	srv := keymintest.NewServer()
+//	defer srv.Close()
+//
+//	client, _ := srv.NewClient()
+//	srv.Inject(keymintest.Fault{Operation: "ActivateKey", Status: 503, Times: 2})
+type Server struct {
+	*httptest.Server
+	// Fake holds the state served by the server. It can be used directly to seed data.
+	Fake *Fake
+
+	mu       sync.Mutex
+	faults   []*Fault
+	requests map[string]int
+}
+
+// Fault describes a failure injected by Server. Latency can be combined with any other
+// fault; otherwise the first of ResetConnection, Status, TruncateBody and MalformedJSON
+// that is set applies.
+type Fault struct {
+	// Operation restricts the fault to the requests of one keymint.Client method
+	// (e.g. "ActivateKey"). Empty means every request.
+	Operation string
+	// Times is the number of requests the fault applies to. Zero means every request
+	// until ClearFaults is called.
+	Times int
+	// Latency delays the response.
+	Latency time.Duration
+	// ResetConnection closes the connection without answering.
+	ResetConnection bool
+	// Status answers with this HTTP status and an error body, without reaching the fake.
+	Status int
+	// RetryAfter sets the Retry-After header of a Status fault, rounded up to the second.
+	RetryAfter time.Duration
+	// TruncateBody processes the request, then closes the connection halfway through the
+	// response body, after announcing its full length.
+	TruncateBody bool
+	// MalformedJSON processes the request, then answers with an invalid JSON body.
+	MalformedJSON bool
+}
+
+// NewServer starts a fake API server backed by a new Fake configured with opts.
+// The caller must call Close when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		Fake:     New(opts...),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient creates a keymint.Client sending its requests to the server with APIKey.
+func (s *Server) NewClient(opts ...keymint.Option) (*keymint.Client, error) {
+	return keymint.New(APIKey, s.URL, opts...)
+}
+
+// Inject queues faults. Each request triggers the first queued fault matching it, if any.
+func (s *Server) Inject(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, fault := range faults {
+		s.faults = append(s.faults, &fault)
+	}
+}
+
+// ClearFaults removes every queued fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the number of HTTP requests received for operation, identified by the
+// keymint.Client method name (e.g. "ActivateKey"), including the ones that hit a fault.
+func (s *Server) Requests(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[operation]
+}
+
+// nextFault records a request for operation and returns the fault it triggers, or nil.
+func (s *Server) nextFault(operation string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[operation]++
+	for i, fault := range s.faults {
+		if fault.Operation != "" && fault.Operation != operation {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		applied := *fault
+		return &applied
+	}
+	return nil
+}
+
+// serveHTTP applies the fault triggered by r, if any, checks the API key and serves r from the fake.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fault := s.nextFault(operationOf(r))
+	if fault == nil {
+		fault = &Fault{}
+	}
+
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	switch {
+	case fault.ResetConnection:
+		resetConnection(w)
+		return
+	case fault.Status != 0:
+		if fault.RetryAfter > 0 {
+			seconds := (fault.RetryAfter + time.Second - 1) / time.Second
+			w.Header().Set("Retry-After", strconv.Itoa(int(seconds)))
+		}
+		writeJSON(w, fault.Status, errorBody{Message: http.StatusText(fault.Status), Code: fault.Status})
+		return
+	}
+
+	api := RequireAPIKey(APIKey, s.Fake)
+	if !fault.TruncateBody && !fault.MalformedJSON {
+		api.ServeHTTP(w, r)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, r)
+	body := rec.Body.Bytes()
+
+	if fault.TruncateBody {
+		truncateResponse(w, rec.Code, rec.Header(), body)
+		return
+	}
+	for name, values := range rec.Header() {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(body[:len(body)/2])
+}
+
+// RequireAPIKey returns a handler rejecting the requests that do not carry apiKey as their
+// bearer token with a 401, like the API does, and passing the others to next.
+func RequireAPIKey(apiKey string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+apiKey {
+			writeError(w, apiError(401, codeInvalidAPIKey, "Invalid API key"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// resetConnection closes the connection of w without answering, with a TCP reset if possible.
+func resetConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
+
+// truncateResponse writes the headers of a response announcing the full length of body,
+// then only half of body, and closes the connection.
+func truncateResponse(w http.ResponseWriter, status int, header http.Header, body []byte) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	defer conn.Close()
+
+	header = header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Set("Connection", "close")
+
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	_ = header.Write(buf)
+	_, _ = buf.WriteString("\r\n")
+	_, _ = buf.Write(body[:len(body)/2])
+	_ = buf.Flush()
+}
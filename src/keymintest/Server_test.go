@@ -0,0 +1,124 @@
+package keymintest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+	"github.com/keymint-dev/keymint-go/src/keymintest"
+)
+
+// fastRetries retries without noticeable delays, and honors Retry-After up to 2 seconds.
+var fastRetries = keymint.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetryAfter: 2 * time.Second}
+
+func TestServerFaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		faults       []keymintest.Fault
+		policy       keymint.RetryPolicy
+		wantErr      error
+		wantJSONErr  bool
+		wantRequests int
+		wantElapsed  time.Duration
+	}{
+		{name: "no fault", wantRequests: 1},
+		{name: "latency", faults: []keymintest.Fault{{Latency: 50 * time.Millisecond, Times: 1}}, wantRequests: 1, wantElapsed: 50 * time.Millisecond},
+		{name: "reset once", faults: []keymintest.Fault{{ResetConnection: true, Times: 1}}, wantRequests: 2},
+		{name: "reset", faults: []keymintest.Fault{{ResetConnection: true}}, wantErr: keymint.ErrNetwork, wantRequests: 3},
+		{name: "status twice", faults: []keymintest.Fault{{Status: 503, Times: 2}}, wantRequests: 3},
+		{name: "status", faults: []keymintest.Fault{{Status: 500}}, wantErr: keymint.ErrServer, wantRequests: 3},
+		{name: "retry after", faults: []keymintest.Fault{{Status: 429, RetryAfter: 500 * time.Millisecond, Times: 1}}, wantRequests: 2, wantElapsed: time.Second},
+		{name: "retry after too long", faults: []keymintest.Fault{{Status: 429, RetryAfter: 3 * time.Second}}, wantErr: keymint.ErrRateLimited, wantRequests: 1},
+		{name: "truncated body", faults: []keymintest.Fault{{TruncateBody: true, Times: 1}}, wantRequests: 2},
+		{name: "malformed JSON", faults: []keymintest.Fault{{MalformedJSON: true}}, wantJSONErr: true, wantRequests: 1},
+		{name: "other operation", faults: []keymintest.Fault{{Operation: "ActivateKey", Status: 500}}, wantRequests: 1},
+		{name: "queued faults", faults: []keymintest.Fault{{Status: 503, Times: 1}, {ResetConnection: true, Times: 1}}, wantRequests: 3},
+		{name: "no retries", faults: []keymintest.Fault{{Status: 503, Times: 1}}, policy: keymint.RetryPolicy{MaxAttempts: 1}, wantErr: keymint.ErrServer, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := keymintest.NewServer()
+			defer srv.Close()
+			key, err := srv.Fake.CreateKey(keymint.CreateKeyParams{ProductID: "p"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			policy := fastRetries
+			if tt.policy.MaxAttempts != 0 {
+				policy = tt.policy
+			}
+			client, err := srv.NewClient(keymint.WithRetryPolicy(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv.Inject(tt.faults...)
+
+			start := time.Now()
+			res, err := client.GetKey(keymint.GetKeyParams{ProductID: "p", LicenseKey: key.Key})
+			elapsed := time.Since(start)
+
+			switch {
+			case tt.wantJSONErr:
+				var syntaxErr *json.SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Errorf("got error %v, want a JSON syntax error", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got error %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("got error %v", err)
+			case res.Data.License.Key != key.Key:
+				t.Errorf("got key %q, want %q", res.Data.License.Key, key.Key)
+			}
+			if got := srv.Requests("GetKey"); got != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", got, tt.wantRequests)
+			}
+			if elapsed < tt.wantElapsed {
+				t.Errorf("the call took %v, want at least %v", elapsed, tt.wantElapsed)
+			}
+		})
+	}
+}
+
+func TestServerClearFaults(t *testing.T) {
+	srv := keymintest.NewServer()
+	defer srv.Close()
+	client, err := srv.NewClient(keymint.WithRetryPolicy(keymint.RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Inject(keymintest.Fault{Operation: "CreateKey", Status: 503})
+	if _, err := client.CreateKey(keymint.CreateKeyParams{ProductID: "p"}); !errors.Is(err, keymint.ErrServer) {
+		t.Fatalf("got error %v, want ErrServer", err)
+	}
+	srv.ClearFaults()
+	if _, err := client.CreateKey(keymint.CreateKeyParams{ProductID: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.Requests("CreateKey"); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+	if n := srv.Fake.Calls("CreateKey"); n != 1 {
+		t.Errorf("the fake served %d calls, want only the one without a fault", n)
+	}
+}
+
+func TestServerRejectsOtherAPIKeys(t *testing.T) {
+	srv := keymintest.NewServer()
+	defer srv.Close()
+	client, err := keymint.New("km_live_other", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetCustomerById(keymint.GetCustomerByIdParams{CustomerID: "c"}); !errors.Is(err, keymint.ErrInvalidAPIKey) {
+		t.Errorf("got error %v, want ErrInvalidAPIKey", err)
+	}
+}
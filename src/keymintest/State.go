@@ -0,0 +1,167 @@
+package keymintest
+
+import (
+	"fmt"
+
+	keymint "github.com/keymint-dev/keymint-go/src"
+)
+
+// State is a serializable copy of the fake's data, e.g. to persist it across runs.
+type State struct {
+	// Customers holds the customers, in creation order.
+	Customers []keymint.Customer `json:"customers"`
+	// Keys holds the license keys, in creation order.
+	Keys []KeyState `json:"keys"`
+	// Sessions holds the live floating sessions.
+	Sessions []SessionState `json:"sessions"`
+}
+
+// KeyState is a license key stored by the fake.
+type KeyState struct {
+	// ID is the license key ID.
+	ID string `json:"id"`
+	// Key is the license key.
+	Key string `json:"key"`
+	// ProductID is the product the key belongs to.
+	ProductID string `json:"productId"`
+	// MaxActivations is the maximum number of activations, or 0 for unlimited.
+	MaxActivations int `json:"maxActivations"`
+	// ExpiresAt is when the key expires, or the zero Time if it never expires.
+	ExpiresAt keymint.Time `json:"expiresAt,omitzero"`
+	// CustomerID is the ID of the customer owning the key, if any.
+	CustomerID string `json:"customerId,omitempty"`
+	// VersionID is the optional associated product version ID.
+	VersionID *string `json:"versionId,omitempty"`
+	// Metadata is the optional custom dictionary attached to the key.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// AllowedHosts is the optional list of authorized machine IDs.
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+	// Blocked indicates if the key is blocked.
+	Blocked bool `json:"blocked"`
+	// Devices is the list of activated devices.
+	Devices []keymint.DeviceDetails `json:"devices"`
+}
+
+// SessionState is a floating session stored by the fake.
+type SessionState struct {
+	// ID is the session ID.
+	ID string `json:"id"`
+	// Secret is the session secret the heartbeats are signed with.
+	Secret string `json:"secret"`
+	// Nonce is the nonce expected by the next heartbeat or checkin.
+	Nonce string `json:"nonce"`
+	// ProductID is the product of the checked out license key.
+	ProductID string `json:"productId"`
+	// LicenseKey is the checked out license key.
+	LicenseKey string `json:"licenseKey"`
+	// HostID is the host holding the session.
+	HostID string `json:"hostId"`
+	// ExpiresAt is when the session expires unless it receives a heartbeat.
+	ExpiresAt keymint.Time `json:"expiresAt"`
+}
+
+// Snapshot returns a copy of the fake's data.
+func (f *Fake) Snapshot() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := State{
+		Customers: []keymint.Customer{},
+		Keys:      []KeyState{},
+		Sessions:  []SessionState{},
+	}
+	for _, c := range f.customers {
+		state.Customers = append(state.Customers, *c)
+	}
+	for _, l := range f.licenses {
+		state.Keys = append(state.Keys, KeyState{
+			ID:             l.id,
+			Key:            l.key,
+			ProductID:      l.productID,
+			MaxActivations: l.maxActivations,
+			ExpiresAt:      l.expiresAt,
+			CustomerID:     l.customerID,
+			VersionID:      cloneString(l.versionID),
+			Metadata:       cloneMap(l.metadata),
+			AllowedHosts:   cloneStrings(l.allowedHosts),
+			Blocked:        l.blocked,
+			Devices:        append([]keymint.DeviceDetails{}, l.devices...),
+		})
+	}
+	for _, s := range f.sessions {
+		state.Sessions = append(state.Sessions, SessionState{
+			ID:         s.id,
+			Secret:     s.secret,
+			Nonce:      s.nonce,
+			ProductID:  s.license.productID,
+			LicenseKey: s.license.key,
+			HostID:     s.hostID,
+			ExpiresAt:  keymint.NewTime(s.expiresAt),
+		})
+	}
+	return state
+}
+
+// Restore replaces the fake's data with state. It fails, leaving the fake unchanged,
+// if a session refers to a license key missing from state. It also forgets the idempotency
+// keys of past calls.
+func (f *Fake) Restore(state State) error {
+	var (
+		customers []*keymint.Customer
+		licenses  []*license
+		sessions  = make(map[string]*session)
+	)
+
+	for _, c := range state.Customers {
+		customers = append(customers, &c)
+	}
+	for _, k := range state.Keys {
+		licenses = append(licenses, &license{
+			id:             k.ID,
+			key:            k.Key,
+			productID:      k.ProductID,
+			maxActivations: k.MaxActivations,
+			expiresAt:      k.ExpiresAt,
+			customerID:     k.CustomerID,
+			versionID:      cloneString(k.VersionID),
+			metadata:       cloneMap(k.Metadata),
+			allowedHosts:   cloneStrings(k.AllowedHosts),
+			blocked:        k.Blocked,
+			devices:        append([]keymint.DeviceDetails(nil), k.Devices...),
+		})
+	}
+	for _, s := range state.Sessions {
+		var owner *license
+		for _, l := range licenses {
+			if l.key == s.LicenseKey && l.productID == s.ProductID {
+				owner = l
+			}
+		}
+		if owner == nil {
+			return fmt.Errorf("keymintest: session %s refers to unknown license key %s", s.ID, s.LicenseKey)
+		}
+		sessions[s.ID] = &session{
+			id:        s.ID,
+			secret:    s.Secret,
+			nonce:     s.Nonce,
+			license:   owner,
+			hostID:    s.HostID,
+			expiresAt: s.ExpiresAt.Time,
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.customers, f.licenses, f.sessions = customers, licenses, sessions
+	f.idempotent = make(map[string]interface{})
+	return nil
+}
+
+// ExpireSessions ends the floating sessions that missed their heartbeats, reporting an
+// EventSessionExpired for each. The fake does it lazily on every floating license call;
+// call ExpireSessions periodically to be notified as soon as sessions expire.
+func (f *Fake) ExpireSessions() {
+	f.mu.Lock()
+	defer f.end()
+	f.expireSessions()
+}